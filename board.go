@@ -0,0 +1,673 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+	"trello_cli/trello"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+var (
+	columnStyle = lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("240")).
+			Padding(0, 1)
+	activeColumnStyle = columnStyle.BorderForeground(lipgloss.Color("205"))
+	columnHeaderStyle = lipgloss.NewStyle().Bold(true)
+	cardIDStyle       = lipgloss.NewStyle().Foreground(lipgloss.Color("3"))
+	selectedCardStyle = lipgloss.NewStyle().Reverse(true)
+	mineMarkerStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
+	statusBarStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+)
+
+const minColumnWidth = 26
+
+type (
+	boardLoadedMsg struct {
+		userID  string
+		lists   []trello.List
+		cards   []trello.Card
+		labels  []trello.Label
+		members []trello.Member
+	}
+
+	cardDetailMsg struct {
+		cardID   string
+		markdown string
+	}
+
+	boardErrMsg struct {
+		err error
+	}
+)
+
+type boardModel struct {
+	client       *trello.Client
+	boardID      string
+	glamourStyle string
+
+	userID  string
+	lists   []trello.List
+	cards   []trello.Card
+	labels  []trello.Label
+	members []trello.Member
+
+	// columns[i] holds indices into cards for lists[i] after filtering
+	columns   [][]int
+	col       int
+	rows      []int
+	colOffset int
+
+	mine      bool
+	filter    textinput.Model
+	filtering bool
+
+	comment        textinput.Model
+	commenting     bool
+	commentCard    string
+	labelPicker    *pickerModel
+	memberPicker   *pickerModel
+	pending        map[string]*pendingCard // cards with updates in flight
+	actionSeq      int
+	confirmArchive bool
+
+	detail     viewport.Model
+	showDetail bool
+	detailCard string
+
+	loading bool
+	status  string
+	width   int
+	height  int
+}
+
+func runBoardCommand(args []string) {
+	fs := flag.NewFlagSet("board", flag.ExitOnError)
+	mine := fs.Bool("mine", false, "Start with only cards assigned to you")
+	addClientFlags(fs)
+	fs.Parse(args)
+
+	cfg, client := mustLoadClient()
+
+	// Query the background before Bubble Tea takes over the terminal
+	style := "dark"
+	if !lipgloss.HasDarkBackground() {
+		style = "light"
+	}
+
+	p := tea.NewProgram(newBoardModel(client, cfg.BoardID, style, *mine), tea.WithAltScreen())
+	if _, err := p.Run(); err != nil {
+		log.Fatalf("Board view failed: %v", err)
+	}
+}
+
+func newBoardModel(client *trello.Client, boardID, glamourStyle string, mine bool) boardModel {
+	filter := textinput.New()
+	filter.Prompt = "/"
+	filter.Placeholder = "filter cards"
+	filter.Width = 30
+
+	comment := textinput.New()
+	comment.Prompt = "Comment: "
+	comment.Placeholder = "enter to post, esc to cancel"
+	comment.Width = 60
+
+	return boardModel{
+		client:       client,
+		boardID:      boardID,
+		glamourStyle: glamourStyle,
+		mine:         mine,
+		filter:       filter,
+		comment:      comment,
+		pending:      make(map[string]*pendingCard),
+		loading:      true,
+		status:       "Loading board...",
+		width:        80,
+		height:       24,
+	}
+}
+
+func loadBoard(client *trello.Client, boardID string) tea.Cmd {
+	return func() tea.Msg {
+		userID, err := client.GetMemberID()
+		if err != nil {
+			return boardErrMsg{fmt.Errorf("failed to get user ID: %w", err)}
+		}
+		lists, err := client.GetLists(boardID)
+		if err != nil {
+			return boardErrMsg{fmt.Errorf("failed to get lists: %w", err)}
+		}
+		cards, err := client.GetCards(boardID)
+		if err != nil {
+			return boardErrMsg{fmt.Errorf("failed to get cards: %w", err)}
+		}
+		labels, err := client.GetLabels(boardID)
+		if err != nil {
+			return boardErrMsg{fmt.Errorf("failed to get labels: %w", err)}
+		}
+		members, err := client.GetBoardMembers(boardID)
+		if err != nil {
+			return boardErrMsg{fmt.Errorf("failed to get members: %w", err)}
+		}
+		return boardLoadedMsg{userID: userID, lists: lists, cards: cards, labels: labels, members: members}
+	}
+}
+
+// loadCardDetail builds the same markdown as showCardDetails and renders it
+// for the detail pane.
+func loadCardDetail(client *trello.Client, lists []trello.List, cardID, style string, width int) tea.Cmd {
+	return func() tea.Msg {
+		detailedCard, err := client.GetCardDetails(cardID)
+		if err != nil {
+			return boardErrMsg{fmt.Errorf("failed to get card details: %w", err)}
+		}
+
+		listMap := make(map[string]string)
+		for _, list := range lists {
+			listMap[list.ID] = list.Name
+		}
+
+		r, err := glamour.NewTermRenderer(
+			glamour.WithStandardStyle(style),
+			glamour.WithWordWrap(width),
+		)
+		if err != nil {
+			return boardErrMsg{err}
+		}
+		out, err := r.Render(buildCardMarkdown(client, detailedCard, detailedCard.Comments, listMap))
+		if err != nil {
+			return boardErrMsg{fmt.Errorf("failed to render markdown: %w", err)}
+		}
+		return cardDetailMsg{cardID: cardID, markdown: out}
+	}
+}
+
+func (m boardModel) Init() tea.Cmd {
+	return loadBoard(m.client, m.boardID)
+}
+
+func (m *boardModel) isMine(card trello.Card) bool {
+	for _, memberID := range card.IDMembers {
+		if memberID == m.userID {
+			return true
+		}
+	}
+	return false
+}
+
+// rebuildColumns applies the "mine" toggle and text filter to the cards and
+// keeps the cursor within bounds.
+func (m *boardModel) rebuildColumns() {
+	listIndex := make(map[string]int, len(m.lists))
+	for i, list := range m.lists {
+		listIndex[list.ID] = i
+	}
+
+	pattern := strings.TrimSpace(m.filter.Value())
+	m.columns = make([][]int, len(m.lists))
+	for i, card := range m.cards {
+		col, ok := listIndex[card.IDList]
+		if !ok {
+			continue
+		}
+		if m.mine && !m.isMine(card) {
+			continue
+		}
+		if pattern != "" && len(fuzzyFilter(pattern, []string{cardSearchText(card)})) == 0 {
+			continue
+		}
+		m.columns[col] = append(m.columns[col], i)
+	}
+
+	if len(m.rows) != len(m.lists) {
+		m.rows = make([]int, len(m.lists))
+	}
+	m.clamp()
+}
+
+// cardSearchText is the text matched by the board filter.
+func cardSearchText(card trello.Card) string {
+	return fmt.Sprintf("#%d %s", card.IDShort, card.Name)
+}
+
+func (m *boardModel) clamp() {
+	if m.col >= len(m.lists) {
+		m.col = len(m.lists) - 1
+	}
+	if m.col < 0 {
+		m.col = 0
+	}
+	for i := range m.rows {
+		if m.rows[i] >= len(m.columns[i]) {
+			m.rows[i] = len(m.columns[i]) - 1
+		}
+		if m.rows[i] < 0 {
+			m.rows[i] = 0
+		}
+	}
+
+	// Keep the selected column in the horizontal window
+	visible := m.visibleColumns()
+	if m.col < m.colOffset {
+		m.colOffset = m.col
+	}
+	if m.col >= m.colOffset+visible {
+		m.colOffset = m.col - visible + 1
+	}
+}
+
+func (m boardModel) visibleColumns() int {
+	n := m.width / minColumnWidth
+	if n < 1 {
+		n = 1
+	}
+	if n > len(m.lists) && len(m.lists) > 0 {
+		n = len(m.lists)
+	}
+	return n
+}
+
+// selectedCard returns the card under the cursor, or nil for an empty column.
+func (m boardModel) selectedCard() *trello.Card {
+	if m.col >= len(m.columns) || len(m.columns[m.col]) == 0 {
+		return nil
+	}
+	return &m.cards[m.columns[m.col][m.rows[m.col]]]
+}
+
+func (m boardModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.detail.Width = msg.Width
+		m.detail.Height = msg.Height - 2
+		m.clamp()
+		return m, nil
+
+	case boardLoadedMsg:
+		m.loading = false
+		m.status = ""
+		m.userID = msg.userID
+		m.lists = msg.lists
+		m.cards = msg.cards
+		m.labels = msg.labels
+		m.members = msg.members
+		m.rebuildColumns()
+		return m, nil
+
+	case cardActionMsg:
+		return m.handleCardAction(msg), nil
+
+	case commentResultMsg:
+		if msg.err != nil {
+			// Reopen the prompt with the draft so nothing typed is lost
+			m.status = fmt.Sprintf("Error: %v (comment not posted)", msg.err)
+			m.commenting = true
+			m.commentCard = msg.cardID
+			m.comment.SetValue(msg.draft)
+			return m, m.comment.Focus()
+		}
+		m.status = "Comment posted"
+		return m, nil
+
+	case cardDetailMsg:
+		m.loading = false
+		m.status = ""
+		m.detail = viewport.New(m.width, m.height-2)
+		m.detail.SetContent(msg.markdown)
+		m.detailCard = msg.cardID
+		m.showDetail = true
+		return m, nil
+
+	case boardErrMsg:
+		m.loading = false
+		m.status = "Error: " + msg.err.Error()
+		return m, nil
+
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" {
+			return m, tea.Quit
+		}
+		if m.showDetail {
+			return m.updateDetail(msg)
+		}
+		if m.labelPicker != nil {
+			return m.updateLabelPicker(msg)
+		}
+		if m.memberPicker != nil {
+			return m.updateMemberPicker(msg)
+		}
+		if m.commenting {
+			return m.updateComment(msg)
+		}
+		if m.confirmArchive {
+			m.confirmArchive = false
+			if msg.String() == "y" {
+				return m, m.archiveSelected()
+			}
+			m.status = "Archive cancelled"
+			return m, nil
+		}
+		if m.filtering {
+			return m.updateFilter(msg)
+		}
+		return m.updateBoard(msg)
+	}
+
+	if m.showDetail {
+		var cmd tea.Cmd
+		m.detail, cmd = m.detail.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+func (m boardModel) updateDetail(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q", "backspace":
+		m.showDetail = false
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.detail, cmd = m.detail.Update(msg)
+	return m, cmd
+}
+
+func (m boardModel) updateFilter(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.filter.SetValue("")
+		fallthrough
+	case "enter":
+		m.filtering = false
+		m.filter.Blur()
+		m.rebuildColumns()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.filter, cmd = m.filter.Update(msg)
+	m.rebuildColumns()
+	return m, cmd
+}
+
+func (m boardModel) updateComment(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.commenting = false
+		m.comment.Blur()
+		m.comment.SetValue("")
+		return m, nil
+	case "enter":
+		text := strings.TrimSpace(m.comment.Value())
+		m.commenting = false
+		m.comment.Blur()
+		m.comment.SetValue("")
+		if text == "" {
+			return m, nil
+		}
+		m.status = "Posting comment..."
+		return m, postComment(m.client, m.commentCard, text)
+	}
+
+	var cmd tea.Cmd
+	m.comment, cmd = m.comment.Update(msg)
+	return m, cmd
+}
+
+func (m boardModel) updateLabelPicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	picker, cmd := m.labelPicker.Update(msg)
+	if !picker.done {
+		m.labelPicker = &picker
+		return m, cmd
+	}
+
+	m.labelPicker = nil
+	if picker.chosen < 0 {
+		return m, nil
+	}
+	return m, m.toggleLabelOnSelected(m.labels[picker.chosen])
+}
+
+func (m boardModel) updateMemberPicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	picker, cmd := m.memberPicker.Update(msg)
+	if !picker.done {
+		m.memberPicker = &picker
+		return m, cmd
+	}
+
+	m.memberPicker = nil
+	if picker.chosen < 0 {
+		return m, nil
+	}
+	return m, m.toggleMemberOnSelected(m.members[picker.chosen])
+}
+
+// pickerHeight is how many rows a picker overlay can list, leaving room for
+// its title, filter and help lines.
+func (m boardModel) pickerHeight() int {
+	return max(m.height-5, 1)
+}
+
+func (m boardModel) updateBoard(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.loading {
+		if msg.String() == "q" {
+			return m, tea.Quit
+		}
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "q":
+		return m, tea.Quit
+	case "left", "h":
+		m.col--
+	case "right", "l":
+		m.col++
+	case "up", "k":
+		if len(m.rows) > 0 {
+			m.rows[m.col]--
+		}
+	case "down", "j":
+		if len(m.rows) > 0 {
+			m.rows[m.col]++
+		}
+	case "g", "home":
+		if len(m.rows) > 0 {
+			m.rows[m.col] = 0
+		}
+	case "G", "end":
+		if len(m.columns) > 0 {
+			m.rows[m.col] = len(m.columns[m.col]) - 1
+		}
+	case "m":
+		m.mine = !m.mine
+		m.rebuildColumns()
+	case "/":
+		m.filtering = true
+		return m, m.filter.Focus()
+	case "esc":
+		if m.filter.Value() != "" {
+			m.filter.SetValue("")
+			m.rebuildColumns()
+		}
+	case "r":
+		m.loading = true
+		m.status = "Refreshing..."
+		return m, loadBoard(m.client, m.boardID)
+	case "enter":
+		if card := m.selectedCard(); card != nil {
+			m.loading = true
+			m.status = fmt.Sprintf("Loading #%d...", card.IDShort)
+			return m, loadCardDetail(m.client, m.lists, card.ID, m.glamourStyle, m.width-4)
+		}
+	case "<", "shift+left", "H":
+		return m, m.moveSelected(-1)
+	case ">", "shift+right", "L":
+		return m, m.moveSelected(1)
+	case "a":
+		return m, m.toggleSelfOnSelected()
+	case "A":
+		if card := m.selectedCard(); card != nil {
+			if len(m.members) == 0 {
+				m.status = "This board has no members"
+				return m, nil
+			}
+			picker := newMemberPicker(m.members, *card)
+			picker.height = m.pickerHeight()
+			m.memberPicker = &picker
+			return m, picker.Init()
+		}
+	case "c":
+		if card := m.selectedCard(); card != nil {
+			m.commenting = true
+			m.commentCard = card.ID
+			m.comment.Prompt = fmt.Sprintf("Comment on #%d: ", card.IDShort)
+			return m, m.comment.Focus()
+		}
+	case "t":
+		if card := m.selectedCard(); card != nil {
+			if len(m.labels) == 0 {
+				m.status = "This board has no labels"
+				return m, nil
+			}
+			picker := newLabelPicker(m.labels, *card)
+			picker.height = m.pickerHeight()
+			m.labelPicker = &picker
+			return m, picker.Init()
+		}
+	case "x":
+		if card := m.selectedCard(); card != nil {
+			m.confirmArchive = true
+			m.status = fmt.Sprintf("Archive #%d %q? (y/n)", card.IDShort, card.Name)
+		}
+	case "o":
+		if card := m.selectedCard(); card != nil {
+			if err := openBrowser(cardURL(*card)); err != nil {
+				m.status = fmt.Sprintf("Error: could not open browser: %v", err)
+			} else {
+				m.status = "Opened " + cardURL(*card)
+			}
+		}
+	}
+
+	m.clamp()
+	return m, nil
+}
+
+func (m boardModel) View() string {
+	if m.showDetail {
+		help := statusBarStyle.Render(fmt.Sprintf("%3.f%% • ↑/↓ scroll • esc back", m.detail.ScrollPercent()*100))
+		return m.detail.View() + "\n\n" + help
+	}
+
+	if m.labelPicker != nil {
+		return m.labelPicker.View()
+	}
+	if m.memberPicker != nil {
+		return m.memberPicker.View()
+	}
+
+	if len(m.lists) == 0 {
+		if m.loading {
+			return m.status
+		}
+		return m.status + "\nThis board has no lists. Press q to quit."
+	}
+
+	visible := m.visibleColumns()
+	// The border sits outside the style width
+	width := m.width/visible - 2
+	if width < 12 {
+		width = 12
+	}
+	// Borders, header, its spacing and the two status lines
+	cardRows := m.height - 7
+	if cardRows < 1 {
+		cardRows = 1
+	}
+
+	var columns []string
+	for i := m.colOffset; i < len(m.lists) && i < m.colOffset+visible; i++ {
+		columns = append(columns, m.renderColumn(i, width, cardRows))
+	}
+	board := lipgloss.JoinHorizontal(lipgloss.Top, columns...)
+
+	return board + "\n" + m.statusLine()
+}
+
+// renderColumn draws list i as a bordered column; width includes the
+// horizontal padding but not the border.
+func (m boardModel) renderColumn(i, width, rows int) string {
+	content := width - 2
+	header := fmt.Sprintf("%s (%d)", m.lists[i].Name, len(m.columns[i]))
+	lines := []string{columnHeaderStyle.Render(runewidth.Truncate(header, content, "…")), ""}
+
+	// Scroll so the selected card stays visible
+	offset := 0
+	if m.rows[i] >= rows {
+		offset = m.rows[i] - rows + 1
+	}
+
+	for r := offset; r < len(m.columns[i]) && r < offset+rows; r++ {
+		card := m.cards[m.columns[i][r]]
+
+		marker := " "
+		if !m.mine && m.isMine(card) {
+			marker = mineMarkerStyle.Render("●")
+		}
+		id := fmt.Sprintf("#%d", card.IDShort)
+		// The mine marker and a space precede the text
+		textWidth := content - 2
+		title := runewidth.Truncate(card.Name, textWidth-runewidth.StringWidth(id)-1, "…")
+		text := runewidth.FillRight(id+" "+title, textWidth)
+
+		if i == m.col && r == m.rows[i] {
+			lines = append(lines, marker+" "+selectedCardStyle.Render(text))
+		} else {
+			lines = append(lines, marker+" "+cardIDStyle.Render(id)+text[len(id):])
+		}
+	}
+
+	// Pad short columns so the borders line up
+	for len(lines) < rows+2 {
+		lines = append(lines, "")
+	}
+
+	style := columnStyle
+	if i == m.col {
+		style = activeColumnStyle
+	}
+	return style.Width(width).Render(strings.Join(lines, "\n"))
+}
+
+// statusLine renders two lines: the current status or prompt, then key help.
+func (m boardModel) statusLine() string {
+	var parts []string
+	if m.status != "" {
+		parts = append(parts, m.status)
+	}
+	if m.filtering || m.filter.Value() != "" {
+		parts = append(parts, m.filter.View())
+	}
+	if m.mine {
+		parts = append(parts, "showing: mine")
+	}
+	status := strings.Join(parts, " • ")
+	if m.commenting {
+		status = m.comment.View()
+	}
+
+	help := "←/→ list • ↑/↓ card • enter details • / filter • m mine • r refresh • q quit • " + actionHelp
+	return lipgloss.NewStyle().MaxWidth(m.width).Render(status) + "\n" + statusBarStyle.Render(runewidth.Truncate(help, m.width, "…"))
+}
@@ -0,0 +1,360 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"trello_cli/trello"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// cardActionMsg reports the outcome of an optimistic card update. On failure
+// only that update is undone.
+type cardActionMsg struct {
+	cardID  string
+	seq     int
+	success string
+	err     error
+}
+
+// pendingCard tracks a card with updates still in flight: base is the card
+// as Trello last confirmed it, and actions are the unconfirmed updates shown
+// on top of it, oldest first.
+type pendingCard struct {
+	base    trello.Card
+	actions []pendingAction
+}
+
+type pendingAction struct {
+	seq    int
+	mutate func(*trello.Card)
+}
+
+// commentResultMsg reports a posted comment; on failure the draft is kept so
+// it can be retried.
+type commentResultMsg struct {
+	cardID string
+	draft  string
+	err    error
+}
+
+// findCard returns the index of the card with the given ID in m.cards.
+func (m boardModel) findCard(cardID string) int {
+	for i, card := range m.cards {
+		if card.ID == cardID {
+			return i
+		}
+	}
+	return -1
+}
+
+// replaceCard swaps in a new version of a card, dropping it from the board
+// when it is archived.
+func (m *boardModel) replaceCard(card trello.Card) {
+	i := m.findCard(card.ID)
+	switch {
+	case i < 0 && !card.Closed:
+		m.cards = append(m.cards, card)
+	case i >= 0 && card.Closed:
+		m.cards = append(m.cards[:i:i], m.cards[i+1:]...)
+	case i >= 0:
+		m.cards[i] = card
+	}
+	m.rebuildColumns()
+}
+
+// focusCard moves the cursor onto the card with the given ID if it is visible.
+func (m *boardModel) focusCard(cardID string) {
+	for col, indices := range m.columns {
+		for row, idx := range indices {
+			if m.cards[idx].ID == cardID {
+				m.col = col
+				m.rows[col] = row
+				m.clamp()
+				return
+			}
+		}
+	}
+}
+
+// applyOptimistic updates the card locally right away and runs the API call
+// in the background, reverting the local change if the call fails.
+func (m *boardModel) applyOptimistic(card trello.Card, mutate func(*trello.Card), call func() error, success string) tea.Cmd {
+	p := m.pending[card.ID]
+	if p == nil {
+		p = &pendingCard{base: cloneCard(card)}
+		m.pending[card.ID] = p
+	}
+	m.actionSeq++
+	seq := m.actionSeq
+	p.actions = append(p.actions, pendingAction{seq: seq, mutate: mutate})
+
+	updated := cloneCard(card)
+	mutate(&updated)
+
+	m.replaceCard(updated)
+	m.focusCard(updated.ID)
+	m.status = "Saving..."
+
+	cardID := card.ID
+	return func() tea.Msg {
+		return cardActionMsg{cardID: cardID, seq: seq, success: success, err: call()}
+	}
+}
+
+// cloneCard copies the slices of a card so optimistic edits don't leak into
+// the saved rollback state.
+func cloneCard(card trello.Card) trello.Card {
+	card.IDMembers = append([]string(nil), card.IDMembers...)
+	card.IDLabels = append([]string(nil), card.IDLabels...)
+	card.Labels = append([]trello.Label(nil), card.Labels...)
+	return card
+}
+
+// handleCardAction settles one update. A failed update is dropped and the
+// card redrawn from its confirmed state plus the updates still in flight, so
+// a later update to the same card that succeeds is kept.
+func (m boardModel) handleCardAction(msg cardActionMsg) boardModel {
+	p := m.pending[msg.cardID]
+	if p == nil {
+		return m
+	}
+
+	var action pendingAction
+	for i, a := range p.actions {
+		if a.seq == msg.seq {
+			action = a
+			p.actions = append(p.actions[:i:i], p.actions[i+1:]...)
+			break
+		}
+	}
+	if len(p.actions) == 0 {
+		delete(m.pending, msg.cardID)
+	}
+
+	if msg.err == nil {
+		if action.mutate != nil {
+			p.base = cloneCard(p.base)
+			action.mutate(&p.base)
+		}
+		m.status = msg.success
+		return m
+	}
+
+	card := cloneCard(p.base)
+	for _, a := range p.actions {
+		a.mutate(&card)
+	}
+	m.replaceCard(card)
+	m.focusCard(card.ID)
+	m.status = fmt.Sprintf("Error: %v (change reverted)", msg.err)
+	return m
+}
+
+func (m *boardModel) moveSelected(delta int) tea.Cmd {
+	card := m.selectedCard()
+	if card == nil {
+		return nil
+	}
+	client := m.client
+
+	target := m.listIndex(card.IDList) + delta
+	if target < 0 || target >= len(m.lists) {
+		return nil
+	}
+	list := m.lists[target]
+
+	cardID := card.ID
+	return m.applyOptimistic(*card, func(c *trello.Card) {
+		c.IDList = list.ID
+	}, func() error {
+		return client.MoveCard(cardID, list.ID)
+	}, fmt.Sprintf("Moved #%d to %s", card.IDShort, list.Name))
+}
+
+func (m boardModel) listIndex(listID string) int {
+	for i, list := range m.lists {
+		if list.ID == listID {
+			return i
+		}
+	}
+	return -1
+}
+
+func (m *boardModel) toggleSelfOnSelected() tea.Cmd {
+	card := m.selectedCard()
+	if card == nil {
+		return nil
+	}
+	client := m.client
+
+	cardID, userID := card.ID, m.userID
+	if m.isMine(*card) {
+		return m.applyOptimistic(*card, func(c *trello.Card) {
+			c.IDMembers = removeString(c.IDMembers, userID)
+		}, func() error {
+			return client.RemoveCardMember(cardID, userID)
+		}, fmt.Sprintf("Unassigned yourself from #%d", card.IDShort))
+	}
+
+	return m.applyOptimistic(*card, func(c *trello.Card) {
+		c.IDMembers = append(c.IDMembers, userID)
+	}, func() error {
+		return client.AddCardMember(cardID, userID)
+	}, fmt.Sprintf("Assigned yourself to #%d", card.IDShort))
+}
+
+func (m *boardModel) toggleLabelOnSelected(label trello.Label) tea.Cmd {
+	card := m.selectedCard()
+	if card == nil {
+		return nil
+	}
+	client := m.client
+
+	name := labelDisplayName(label)
+	cardID := card.ID
+	if containsString(card.IDLabels, label.ID) {
+		return m.applyOptimistic(*card, func(c *trello.Card) {
+			c.IDLabels = removeString(c.IDLabels, label.ID)
+			for i, l := range c.Labels {
+				if l.ID == label.ID {
+					c.Labels = append(c.Labels[:i], c.Labels[i+1:]...)
+					break
+				}
+			}
+		}, func() error {
+			return client.RemoveCardLabel(cardID, label.ID)
+		}, fmt.Sprintf("Removed label %s from #%d", name, card.IDShort))
+	}
+
+	return m.applyOptimistic(*card, func(c *trello.Card) {
+		c.IDLabels = append(c.IDLabels, label.ID)
+		c.Labels = append(c.Labels, label)
+	}, func() error {
+		return client.AddCardLabel(cardID, label.ID)
+	}, fmt.Sprintf("Added label %s to #%d", name, card.IDShort))
+}
+
+func (m *boardModel) toggleMemberOnSelected(member trello.Member) tea.Cmd {
+	card := m.selectedCard()
+	if card == nil {
+		return nil
+	}
+	client := m.client
+
+	cardID, memberID := card.ID, member.ID
+	if containsString(card.IDMembers, memberID) {
+		return m.applyOptimistic(*card, func(c *trello.Card) {
+			c.IDMembers = removeString(c.IDMembers, memberID)
+		}, func() error {
+			return client.RemoveCardMember(cardID, memberID)
+		}, fmt.Sprintf("Unassigned @%s from #%d", member.Username, card.IDShort))
+	}
+
+	return m.applyOptimistic(*card, func(c *trello.Card) {
+		c.IDMembers = append(c.IDMembers, memberID)
+	}, func() error {
+		return client.AddCardMember(cardID, memberID)
+	}, fmt.Sprintf("Assigned @%s to #%d", member.Username, card.IDShort))
+}
+
+func (m *boardModel) archiveSelected() tea.Cmd {
+	card := m.selectedCard()
+	if card == nil {
+		return nil
+	}
+	client := m.client
+
+	cardID := card.ID
+	return m.applyOptimistic(*card, func(c *trello.Card) {
+		c.Closed = true
+	}, func() error {
+		return client.ArchiveCard(cardID)
+	}, fmt.Sprintf("Archived #%d", card.IDShort))
+}
+
+func postComment(client *trello.Client, cardID, text string) tea.Cmd {
+	return func() tea.Msg {
+		_, err := client.AddComment(cardID, text)
+		return commentResultMsg{cardID: cardID, draft: text, err: err}
+	}
+}
+
+// newLabelPicker lists the board's labels, marking those on the card.
+func newLabelPicker(labels []trello.Label, card trello.Card) pickerModel {
+	items := labelPickerItems(labels)
+	for i, label := range labels {
+		mark := "  "
+		if containsString(card.IDLabels, label.ID) {
+			mark = "✓ "
+		}
+		items[i].title = mark + items[i].title
+	}
+	return newPicker(fmt.Sprintf("Toggle label on #%d", card.IDShort), items)
+}
+
+// labelPickerItems names unnamed labels by their color, as Trello does.
+func labelPickerItems(labels []trello.Label) []pickerItem {
+	items := make([]pickerItem, len(labels))
+	for i, label := range labels {
+		name := label.Name
+		if name == "" {
+			name = "(" + label.Color + ")"
+		}
+		items[i] = pickerItem{title: name, detail: label.Color}
+	}
+	return items
+}
+
+// newMemberPicker lists the board's members, marking those on the card.
+func newMemberPicker(members []trello.Member, card trello.Card) pickerModel {
+	items := memberPickerItems(members)
+	for i, member := range members {
+		mark := "  "
+		if containsString(card.IDMembers, member.ID) {
+			mark = "✓ "
+		}
+		items[i].title = mark + items[i].title
+	}
+	return newPicker(fmt.Sprintf("Toggle member on #%d", card.IDShort), items)
+}
+
+func labelDisplayName(label trello.Label) string {
+	if label.Name != "" {
+		return label.Name
+	}
+	return "(" + label.Color + ")"
+}
+
+func cardURL(card trello.Card) string {
+	return shortCardURL(card.ShortLink)
+}
+
+// shortCardURL is the stable link to a card, without the title slug.
+func shortCardURL(shortLink string) string {
+	return "https://trello.com/c/" + shortLink
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func removeString(values []string, s string) []string {
+	var out []string
+	for _, v := range values {
+		if v != s {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// actionHelp is shown in the help line after the navigation keys.
+var actionHelp = strings.Join([]string{
+	"</> move", "a assign me", "A assign", "c comment", "t labels", "x archive", "o open",
+}, " • ")
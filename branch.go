@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"trello_cli/config"
+	"trello_cli/trello"
+	"unicode"
+)
+
+// maxSlugLength keeps branch names readable in prompts and PR lists.
+const maxSlugLength = 50
+
+// slugify lowercases text and joins its letters and digits with hyphens,
+// e.g. "Fix: login (SSO) timeout!" becomes "fix-login-sso-timeout".
+func slugify(text string) string {
+	var b strings.Builder
+	pendingDash := false
+	for _, r := range strings.ToLower(text) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if pendingDash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			pendingDash = false
+			continue
+		}
+		pendingDash = true
+	}
+
+	slug := b.String()
+	runes := []rune(slug)
+	if len(runes) <= maxSlugLength {
+		return slug
+	}
+
+	// Cut at the last word boundary that fits
+	slug = string(runes[:maxSlugLength])
+	if i := strings.LastIndexByte(slug, '-'); i > 0 {
+		slug = slug[:i]
+	}
+	return strings.TrimRight(slug, "-")
+}
+
+// defaultBranchTemplate gives the conventional "123-short-title-slug".
+const defaultBranchTemplate = "{{.Number}}-{{.Slug}}"
+
+// branchData is what branch templates can refer to.
+type branchData struct {
+	Number    int    // card number on the board
+	Slug      string // title as lowercase words joined by hyphens
+	Title     string
+	ShortLink string
+}
+
+// cardBranchName derives a branch name for card from the configured
+// template, "123-short-title-slug" by default.
+func cardBranchName(cfg *config.Config, card trello.Card) (string, error) {
+	text := cfg.BranchTemplate
+	if text == "" {
+		text = defaultBranchTemplate
+	}
+	tmpl, err := template.New("branch").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid branch_template: %w", err)
+	}
+
+	var b strings.Builder
+	data := branchData{Number: card.IDShort, Slug: slugify(card.Name), Title: card.Name, ShortLink: card.ShortLink}
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("invalid branch_template: %w", err)
+	}
+
+	// An empty slug would leave a dangling separator
+	name := strings.Trim(strings.TrimSpace(b.String()), "-_/.")
+	if name == "" {
+		return "", fmt.Errorf("branch_template %q gives an empty branch name for #%d", text, card.IDShort)
+	}
+	return name, nil
+}
+
+// defaultBranchPattern finds the card number in branches named by the
+// default template, with or without a prefix such as feature/.
+const defaultBranchPattern = `(?:^|/)(\d+)(?:-|$)`
+
+// branchCardRef returns the card named by the current git branch, using
+// branch_pattern, along with the branch. It returns an empty ref outside a
+// git repository, on a detached HEAD or when the branch doesn't match.
+func branchCardRef(cfg *config.Config) (ref, branch string, err error) {
+	pattern := cfg.BranchPattern
+	if pattern == "" {
+		pattern = defaultBranchPattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid branch_pattern: %w", err)
+	}
+
+	if checkGitRepo() != nil {
+		return "", "", nil
+	}
+	branch, err = gitOutput("symbolic-ref", "--quiet", "--short", "HEAD")
+	if err != nil {
+		return "", "", nil
+	}
+
+	m := re.FindStringSubmatch(branch)
+	if m == nil {
+		return "", branch, nil
+	}
+	// The first group is the card; a pattern without groups names it whole
+	ref = m[0]
+	if len(m) > 1 {
+		ref = m[1]
+	}
+	if _, err := strconv.Atoi(ref); err == nil {
+		ref = "#" + ref
+	}
+	return ref, branch, nil
+}
+
+// mustBranchCardRef returns the card named by the current git branch,
+// exiting when there is none.
+func mustBranchCardRef(cfg *config.Config) string {
+	ref, branch, err := branchCardRef(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if ref == "" {
+		if branch == "" {
+			log.Fatalf("No card given, and not on a git branch to take it from")
+		}
+		log.Fatalf("No card given, and branch %q doesn't name one (see branch_pattern)", branch)
+	}
+	return ref
+}
+
+// splitCardArgs separates the card from the other arguments of a command
+// taking "[card] <rest>". An explicit reference (#123, 123, a card URL or
+// ID) always comes first; otherwise the card is taken from the git branch,
+// and only failing that is the first argument read as title text.
+func splitCardArgs(cfg *config.Config, args []string) (ref string, rest []string) {
+	if len(args) > 0 && isExplicitCardRef(args[0]) {
+		return args[0], args[1:]
+	}
+
+	ref, branch, err := branchCardRef(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if ref != "" {
+		fmt.Fprintf(os.Stderr, "Using %s from branch %s\n", ref, branch)
+		return ref, args
+	}
+
+	if len(args) == 0 {
+		return mustBranchCardRef(cfg), nil
+	}
+	return args[0], args[1:]
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"trello_cli/config"
+	"trello_cli/store"
+	"trello_cli/trello"
+)
+
+// defaultInProgressList is where 'branch --start' moves cards unless
+// in_progress_list is configured.
+const defaultInProgressList = "In Progress"
+
+func runBranchCommand(args []string) {
+	fs := flag.NewFlagSet("branch", flag.ExitOnError)
+	start := fs.Bool("start", false, "Also move the card to the in-progress list and assign it to you")
+	printOnly := fs.Bool("print", false, "Print the branch name without touching git")
+	addClientFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: trello_cli branch [flags] <card>\n\n")
+		fmt.Fprintf(fs.Output(), "Creates or checks out the git branch for a card, named by branch_template.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	ref := strings.TrimSpace(strings.Join(fs.Args(), " "))
+	if ref == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+	if *printOnly && *start {
+		log.Fatalf("--print cannot be combined with --start")
+	}
+
+	// Check the repository before doing anything on Trello
+	if !*printOnly {
+		if err := checkGitRepo(); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// Only --start changes the card, so only it sends queued changes first
+	var (
+		w    *cardWriter
+		cfg  *config.Config
+		card *trello.DetailedCard
+	)
+	if *start {
+		w = mustLoadWriter(false)
+		cfg, card = w.cfg, w.findCard(ref)
+	} else {
+		var client *trello.Client
+		cfg, client = mustLoadClient()
+		var err error
+		if card, err = resolveCard(client, cfg.BoardID, ref); err != nil {
+			log.Fatalf("Failed to find card: %v", err)
+		}
+	}
+
+	name, err := cardBranchName(cfg, trello.Card{IDShort: card.IDShort, Name: card.Name, ShortLink: card.ShortLink})
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *printOnly {
+		fmt.Println(name)
+		return
+	}
+
+	if _, err := gitOutput("check-ref-format", "--branch", name); err != nil {
+		log.Fatalf("%q is not a valid branch name; check branch_template", name)
+	}
+
+	current, _ := gitOutput("symbolic-ref", "--quiet", "--short", "HEAD")
+	switch {
+	case current == name:
+		fmt.Printf("Already on %s\n", name)
+	case gitBranchExists(name):
+		err = gitRun("checkout", name)
+	default:
+		err = gitRun("checkout", "-b", name)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *start {
+		startCard(w, card)
+	}
+}
+
+// startCard moves card to the in-progress list and assigns it to the
+// current user, skipping whatever is already the case.
+func startCard(w *cardWriter, card *trello.DetailedCard) {
+	listName := w.cfg.InProgressList
+	if listName == "" {
+		listName = defaultInProgressList
+	}
+	list := w.findList(listName)
+
+	if list.ID == card.IDList {
+		fmt.Printf("#%d is already in %s\n", card.IDShort, list.Name)
+	} else {
+		fromName := "Unknown"
+		if card.List != nil {
+			fromName = card.List.Name
+		}
+		w.submit(store.Op{
+			Kind:         store.OpMove,
+			CardID:       card.ID,
+			CardShort:    card.IDShort,
+			CardName:     card.Name,
+			ListID:       list.ID,
+			ListName:     list.Name,
+			FromListID:   card.IDList,
+			FromListName: fromName,
+		})
+	}
+
+	memberID, err := w.reader.GetMemberID()
+	if err != nil {
+		log.Fatalf("Failed to get user ID: %v", err)
+	}
+	if containsString(card.IDMembers, memberID) {
+		return
+	}
+	// Assignments aren't queued, so they need Trello to be reachable
+	if w.snap != nil || w.queueOnly {
+		fmt.Fprintf(os.Stderr, "Not assigning #%d while changes are queued; assign yourself once back online.\n", card.IDShort)
+		return
+	}
+	if err := w.client.AddCardMember(card.ID, memberID); err != nil {
+		log.Fatalf("Failed to assign card: %v", err)
+	}
+	fmt.Printf("Assigned #%d to you\n", card.IDShort)
+}
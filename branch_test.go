@@ -0,0 +1,96 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"trello_cli/config"
+	"trello_cli/trello"
+)
+
+func TestSlugify(t *testing.T) {
+	long := strings.Repeat("word ", 20)
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Fix login", "fix-login"},
+		{"Fix: login (SSO) timeout!", "fix-login-sso-timeout"},
+		{"  --leading and trailing--  ", "leading-and-trailing"},
+		{"v2.1 release", "v2-1-release"},
+		{"Café crème", "café-crème"},
+		{"Ünïcödé TITLE", "ünïcödé-title"},
+		{"日本語 タイトル", "日本語-タイトル"},
+		{"emoji 🚀 launch", "emoji-launch"},
+		{"", ""},
+		{"?!… ---", ""},
+		{long, strings.TrimSuffix(strings.Repeat("word-", 10), "-")},
+		{strings.Repeat("a", 60), strings.Repeat("a", 50)},
+		{strings.Repeat("é", 60), strings.Repeat("é", 50)},
+		{strings.Repeat("x", 49) + " yz", strings.Repeat("x", 49)},
+	}
+
+	for _, tt := range tests {
+		if got := slugify(tt.text); got != tt.want {
+			t.Errorf("slugify(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+		if n := len([]rune(slugify(tt.text))); n > maxSlugLength {
+			t.Errorf("slugify(%q) is %d runes, over %d", tt.text, n, maxSlugLength)
+		}
+	}
+}
+
+func TestCardBranchName(t *testing.T) {
+	card := trello.Card{IDShort: 123, Name: "Fix: login timeout", ShortLink: "AbCd1234"}
+
+	tests := []struct {
+		template string
+		card     trello.Card
+		want     string
+		wantErr  bool
+	}{
+		{"", card, "123-fix-login-timeout", false},
+		{"feature/{{.Number}}-{{.Slug}}", card, "feature/123-fix-login-timeout", false},
+		{"{{.ShortLink}}", card, "AbCd1234", false},
+		{"", trello.Card{IDShort: 7, Name: "!!!"}, "7", false},
+		{"{{.Slug}}", trello.Card{IDShort: 7, Name: "!!!"}, "", true},
+		{"{{.Nope}}", card, "", true},
+		{"{{.Number", card, "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := cardBranchName(&config.Config{BranchTemplate: tt.template}, tt.card)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("cardBranchName(%q, %q) = %q, %v; want %q, error %v", tt.template, tt.card.Name, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestDefaultBranchPattern(t *testing.T) {
+	re := regexp.MustCompile(defaultBranchPattern)
+
+	tests := []struct {
+		branch string
+		want   string // the card number, empty for no match
+	}{
+		{"123-fix-login", "123"},
+		{"feature/123-fix-login", "123"},
+		{"users/ann/45-docs", "45"},
+		{"123", "123"},
+		{"main", ""},
+		{"release-2024", ""},
+		{"v123-fix", ""},
+		{"feature/abc-123", ""},
+	}
+
+	for _, tt := range tests {
+		got := ""
+		if m := re.FindStringSubmatch(tt.branch); m != nil {
+			got = m[1]
+		}
+		if got != tt.want {
+			t.Errorf("branch %q gave card %q, want %q", tt.branch, got, tt.want)
+		}
+	}
+}
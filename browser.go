@@ -0,0 +1,29 @@
+package main
+
+import (
+	"os/exec"
+	"runtime"
+)
+
+const appKeyURL = "https://trello.com/app-key"
+
+// openBrowser opens url with the platform's default handler.
+func openBrowser(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	// Reap the launcher without blocking the caller
+	go cmd.Wait()
+	return nil
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"trello_cli/trello"
+)
+
+const cacheUsage = `Usage: trello_cli cache <command>
+
+Commands:
+  clear   Delete all cached API responses
+  path    Print the cache directory
+`
+
+func runCacheCommand(args []string) {
+	if len(args) != 1 {
+		fmt.Fprint(os.Stderr, cacheUsage)
+		os.Exit(2)
+	}
+
+	dir, err := trello.DefaultCacheDir()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	switch args[0] {
+	case "clear":
+		if err := trello.ClearCache(dir); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println("Cache cleared.")
+	case "path":
+		fmt.Println(dir)
+	case "help", "-h", "--help":
+		fmt.Print(cacheUsage)
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown cache command: %s\n\n%s", args[0], cacheUsage)
+		os.Exit(2)
+	}
+}
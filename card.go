@@ -0,0 +1,272 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"trello_cli/config"
+	"trello_cli/trello"
+
+	"github.com/charmbracelet/glamour"
+)
+
+// runShowCommand shows a card, by default the one the git branch is named
+// after.
+func runShowCommand(args []string) {
+	fs := flag.NewFlagSet("show", flag.ExitOnError)
+	field := fs.String("field", "", "Show only a specific field: title, description, assignees, labels, list, status, created_at")
+	fs.StringVar(field, "f", "", "Show only a specific field (short)")
+	offline := fs.Bool("offline", false, "Read from the snapshot saved by 'trello_cli sync' instead of Trello")
+	addClientFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: trello_cli show [flags] [card]\n\n")
+		fmt.Fprintf(fs.Output(), "Without a card, shows the one named by the current git branch (see branch_pattern).\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	showCardDetails(strings.TrimSpace(strings.Join(fs.Args(), " ")), *field, *offline)
+}
+
+func showCardDetails(ref string, fieldFilter string, offline bool) {
+	cfg, client, reader := mustLoadReader(offline)
+	detailedCard := mustFindCard(cfg, reader, ref)
+
+	// Comments and the list arrive nested in the card response
+	listMap := make(map[string]string)
+	if detailedCard.List != nil {
+		listMap[detailedCard.List.ID] = detailedCard.List.Name
+	}
+
+	// Handle field filtering - if fieldFilter is specified, output only that field
+	if fieldFilter != "" {
+		value, err := cardField(client, detailedCard, listMap, fieldFilter)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Print(value)
+		return
+	}
+
+	out, err := renderMarkdown(buildCardMarkdown(client, detailedCard, detailedCard.Comments, listMap))
+	if err != nil {
+		log.Fatalf("Failed to render markdown: %v", err)
+	}
+
+	fmt.Print(out)
+}
+
+// mustLoadReader returns the config, a client and where to read cards from:
+// Trello, or the board snapshot when offline.
+func mustLoadReader(offline bool) (*config.Config, *trello.Client, boardReader) {
+	if !offline {
+		cfg, client := mustLoadClient()
+		return cfg, client, client
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	snap, client := mustLoadOffline(cfg)
+	return cfg, client, snap
+}
+
+// mustFindCard resolves ref, or the card the git branch is named after when
+// ref is empty, with its comments, members and list.
+func mustFindCard(cfg *config.Config, reader boardReader, ref string) *trello.DetailedCard {
+	if ref == "" {
+		ref = mustBranchCardRef(cfg)
+	}
+
+	// Look the card up with its comments, members and list in one request
+	detailedCard, err := resolveCard(reader, cfg.BoardID, ref)
+	if err != nil {
+		log.Fatalf("Failed to find card: %v", err)
+	}
+	return detailedCard
+}
+
+// buildCardMarkdown assembles the markdown document shown for a card.
+func buildCardMarkdown(client *trello.Client, detailedCard *trello.DetailedCard, comments []trello.Comment, listMap map[string]string) string {
+	var markdown strings.Builder
+
+	// Title
+	markdown.WriteString(fmt.Sprintf("# %s\n\n", detailedCard.Name))
+
+	// Status
+	status := "Open"
+	if detailedCard.Closed {
+		status = "Closed"
+	}
+	markdown.WriteString(fmt.Sprintf("**%s**\n\n", status))
+
+	// Description
+	if detailedCard.Desc != "" {
+		markdown.WriteString(fmt.Sprintf("## Description\n\n%s\n\n", detailedCard.Desc))
+	}
+
+	// Assignees
+	if len(detailedCard.IDMembers) > 0 {
+		markdown.WriteString("## Assignees\n")
+		for _, name := range assigneeNames(client, detailedCard) {
+			markdown.WriteString(fmt.Sprintf("- %s\n", name))
+		}
+		markdown.WriteString("\n")
+	}
+
+	// Labels
+	if len(detailedCard.Labels) > 0 {
+		markdown.WriteString("## Labels\n")
+		for _, label := range detailedCard.Labels {
+			markdown.WriteString(fmt.Sprintf("- %s\n", label.Name))
+		}
+		markdown.WriteString("\n")
+	}
+
+	// Checklists
+	markdown.WriteString(checklistMarkdown(detailedCard.Checklists, "##"))
+
+	// List
+	if listName, exists := listMap[detailedCard.IDList]; exists {
+		markdown.WriteString(fmt.Sprintf("## List\n\n%s\n\n", listName))
+	}
+
+	// Comments
+	if len(comments) > 0 {
+		markdown.WriteString(fmt.Sprintf("## Comments (%d)\n\n", len(comments)))
+		for i, comment := range comments {
+			commentTime, err := time.Parse(time.RFC3339, comment.Date)
+			timeStr := "Unknown time"
+			if err == nil {
+				timeStr = commentTime.Format("Jan 2, 2006 at 3:04 PM")
+			}
+
+			markdown.WriteString(fmt.Sprintf("### Comment %d\n\n", i+1))
+			markdown.WriteString(fmt.Sprintf("**%s** commented on %s:\n\n", comment.MemberCreator.FullName, timeStr))
+			markdown.WriteString(fmt.Sprintf("%s\n\n", comment.Data.Text))
+			markdown.WriteString("---\n\n")
+		}
+	}
+
+	// Card link
+	markdown.WriteString("## Links\n\n")
+	markdown.WriteString(fmt.Sprintf("- View this card on Trello: %s\n", shortCardURL(detailedCard.ShortLink)))
+
+	return markdown.String()
+}
+
+// checklistMarkdown renders checklists as task lists, each under a heading
+// of the given level such as "##".
+func checklistMarkdown(checklists []trello.Checklist, heading string) string {
+	var markdown strings.Builder
+	for _, checklist := range checklists {
+		markdown.WriteString(fmt.Sprintf("%s %s\n\n", heading, checklist.Name))
+		for _, item := range checklist.CheckItems {
+			mark := " "
+			if item.State == "complete" {
+				mark = "x"
+			}
+			markdown.WriteString(fmt.Sprintf("- [%s] %s\n", mark, item.Name))
+		}
+		markdown.WriteString("\n")
+	}
+	return markdown.String()
+}
+
+// assigneeNames returns the full names of the card's members in card order.
+// Members nested in the card response are used first; the rest are resolved
+// through the client's member cache, falling back to the raw ID.
+func assigneeNames(client *trello.Client, detailedCard *trello.DetailedCard) []string {
+	client.PrimeMembers(detailedCard.Members)
+	members, _ := client.ResolveMembers(detailedCard.IDBoard, detailedCard.IDMembers)
+
+	names := make([]string, 0, len(detailedCard.IDMembers))
+	for _, memberID := range detailedCard.IDMembers {
+		if member, ok := members[memberID]; ok {
+			names = append(names, member.FullName)
+		} else {
+			names = append(names, memberID)
+		}
+	}
+	return names
+}
+
+// cardField returns the raw value of a single card field for -f.
+func cardField(client *trello.Client, detailedCard *trello.DetailedCard, listMap map[string]string, field string) (string, error) {
+	switch strings.ToLower(field) {
+	case "title":
+		return detailedCard.Name, nil
+	case "description":
+		return detailedCard.Desc, nil
+	case "status":
+		if detailedCard.Closed {
+			return "Closed", nil
+		}
+		return "Open", nil
+	case "assignees":
+		if len(detailedCard.IDMembers) == 0 {
+			return "No assignees", nil
+		}
+		return strings.Join(assigneeNames(client, detailedCard), ", "), nil
+	case "labels":
+		var labelNames []string
+		for _, label := range detailedCard.Labels {
+			labelNames = append(labelNames, label.Name)
+		}
+		return strings.Join(labelNames, ", "), nil
+	case "list":
+		if listName, exists := listMap[detailedCard.IDList]; exists {
+			return listName, nil
+		}
+		return "Unknown", nil
+	case "link":
+		return shortCardURL(detailedCard.ShortLink), nil
+	case "created_at":
+		if createdAt := cardCreatedAt(detailedCard.ID); !createdAt.IsZero() {
+			return createdAt.Format("2006-01-02 15:04:05"), nil
+		}
+		return "Unknown", nil
+	}
+	return "", fmt.Errorf("Unknown field: %s. Available fields: title, description, status, assignees, labels, list, link, created_at", field)
+}
+
+// cardCreatedAt extracts the creation time from a card ID, whose first 8
+// characters are a hexadecimal Unix timestamp. It returns the zero time for
+// IDs it cannot decode.
+func cardCreatedAt(cardID string) time.Time {
+	if len(cardID) < 8 {
+		return time.Time{}
+	}
+	timestamp, err := strconv.ParseInt(cardID[:8], 16, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(timestamp, 0)
+}
+
+// renderMarkdown renders markdown for the terminal, honoring CLICOLOR_FORCE.
+func renderMarkdown(markdown string) (string, error) {
+	if os.Getenv("CLICOLOR_FORCE") == "1" {
+		// Force ANSI output even when piping
+		os.Setenv("NO_COLOR", "")
+		os.Setenv("COLORTERM", "256color")
+		os.Setenv("TERM", "xterm-256color")
+		// Use Render function with dark style
+		return glamour.Render(markdown, "dark")
+	}
+
+	// Use auto-style for adaptive coloring
+	r, err := glamour.NewTermRenderer(
+		glamour.WithAutoStyle(),
+		glamour.WithWordWrap(0),
+	)
+	if err != nil {
+		return "", err
+	}
+	return r.Render(markdown)
+}
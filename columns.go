@@ -0,0 +1,320 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"trello_cli/trello"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// listColumn is a column the listing can show.
+type listColumn struct {
+	name   string
+	layout tableColumn
+	text   func(row cardRow) string
+	raw    func(row cardRow) string // tsv value when it differs from text
+	style  func(row cardRow) lipgloss.Style
+}
+
+var (
+	idStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("3")) // Yellow color
+	dimStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("8")) // Gray color
+	overdueStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("1")) // Red color
+	plainStyle   = lipgloss.NewStyle()
+)
+
+const columnNames = "id, title, list, board, members, labels, due, activity, age, comments, url"
+
+var listColumns = map[string]listColumn{
+	"id": {
+		text:  func(row cardRow) string { return fmt.Sprintf("#%d", row.card.IDShort) },
+		raw:   func(row cardRow) string { return strconv.Itoa(row.card.IDShort) },
+		style: func(cardRow) lipgloss.Style { return idStyle },
+	},
+	"title": {
+		layout: tableColumn{maxWidth: 80, minWidth: 20},
+		text:   func(row cardRow) string { return row.card.Name },
+	},
+	"list": {
+		layout: tableColumn{maxWidth: 30, minWidth: 8},
+		text:   func(row cardRow) string { return row.listName },
+		style:  func(cardRow) lipgloss.Style { return dimStyle },
+	},
+	"board": {
+		layout: tableColumn{maxWidth: 30, minWidth: 8},
+		text:   func(row cardRow) string { return row.boardName },
+		style:  func(cardRow) lipgloss.Style { return dimStyle },
+	},
+	"members": {
+		layout: tableColumn{maxWidth: 40, minWidth: 10, wrap: true},
+		text: func(row cardRow) string {
+			names := make([]string, 0, len(row.card.IDMembers))
+			for _, id := range row.card.IDMembers {
+				if member, ok := row.members[id]; ok {
+					names = append(names, member.FullName)
+				} else {
+					names = append(names, id)
+				}
+			}
+			return strings.Join(names, ", ")
+		},
+	},
+	"labels": {
+		layout: tableColumn{maxWidth: 40, minWidth: 10, wrap: true},
+		text:   func(row cardRow) string { return strings.Join(cardLabelNames(row.card), ", ") },
+	},
+	"due": {
+		text: func(row cardRow) string {
+			if due := rowDue(row); !due.IsZero() {
+				return formatDate(due)
+			}
+			return ""
+		},
+		raw: func(row cardRow) string {
+			if due := rowDue(row); !due.IsZero() {
+				return due.Local().Format("2006-01-02")
+			}
+			return ""
+		},
+		style: func(row cardRow) lipgloss.Style {
+			if due := rowDue(row); !due.IsZero() && due.Before(time.Now()) {
+				return overdueStyle
+			}
+			return plainStyle
+		},
+	},
+	"activity": {
+		text: func(row cardRow) string {
+			if at := rowActivity(row); !at.IsZero() {
+				return compactAge(time.Since(at)) + " ago"
+			}
+			return ""
+		},
+		raw: func(row cardRow) string { return row.card.DateLastActivity },
+	},
+	"age": {
+		text: func(row cardRow) string {
+			if created := cardCreatedAt(row.card.ID); !created.IsZero() {
+				return compactAge(time.Since(created))
+			}
+			return ""
+		},
+		raw: func(row cardRow) string {
+			if created := cardCreatedAt(row.card.ID); !created.IsZero() {
+				return strconv.Itoa(int(time.Since(created).Hours() / 24))
+			}
+			return ""
+		},
+	},
+	"comments": {
+		text: func(row cardRow) string { return strconv.Itoa(row.card.Badges.Comments) },
+	},
+	"url": {
+		layout: tableColumn{minWidth: 20},
+		text:   func(row cardRow) string { return cardURL(row.card) },
+	},
+}
+
+// parseColumns turns a comma-separated --columns value into columns. An
+// empty spec selects the defaults for the output format.
+func parseColumns(spec, format string, multiBoard bool) ([]listColumn, error) {
+	if strings.TrimSpace(spec) == "" {
+		switch {
+		case format == "tsv" && multiBoard:
+			spec = "id,title,list,board,url"
+		case format == "tsv":
+			spec = "id,title,list,url"
+		case multiBoard:
+			spec = "id,title,board,list"
+		default:
+			spec = "id,title,list"
+		}
+	}
+
+	var columns []listColumn
+	for _, name := range splitList(spec) {
+		column, ok := listColumns[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown column %q (available: %s)", name, columnNames)
+		}
+		column.name = strings.ToLower(name)
+		columns = append(columns, column)
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("no columns given (available: %s)", columnNames)
+	}
+	return columns, nil
+}
+
+// usesColumn reports whether the named column is among columns.
+func usesColumn(columns []listColumn, name string) bool {
+	for _, column := range columns {
+		if column.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// withoutColumns returns columns minus the named ones.
+func withoutColumns(columns []listColumn, names ...string) []listColumn {
+	var kept []listColumn
+	for _, column := range columns {
+		drop := false
+		for _, name := range names {
+			drop = drop || column.name == name
+		}
+		if !drop {
+			kept = append(kept, column)
+		}
+	}
+	return kept
+}
+
+// sortKey is one --sort criterion.
+type sortKey struct {
+	name string
+	desc bool
+}
+
+const sortKeyNames = "board, position, list, activity, due, created, title, id"
+
+// rowComparators order two rows by one criterion, returning <0, 0 or >0.
+var rowComparators = map[string]func(a, b cardRow) int{
+	// board order: lists as arranged on the board, cards by position
+	"board": func(a, b cardRow) int {
+		if c := strings.Compare(a.boardName, b.boardName); c != 0 {
+			return c
+		}
+		if c := compareFloat(a.listPos, b.listPos); c != 0 {
+			return c
+		}
+		return compareFloat(a.card.Pos, b.card.Pos)
+	},
+	"position": func(a, b cardRow) int { return compareFloat(a.card.Pos, b.card.Pos) },
+	"list":     func(a, b cardRow) int { return strings.Compare(a.listName, b.listName) },
+	"activity": func(a, b cardRow) int { return compareTime(rowActivity(a), rowActivity(b)) },
+	"due":      func(a, b cardRow) int { return compareTime(rowDue(a), rowDue(b)) },
+	"created": func(a, b cardRow) int {
+		return compareTime(cardCreatedAt(a.card.ID), cardCreatedAt(b.card.ID))
+	},
+	"title": func(a, b cardRow) int {
+		return strings.Compare(strings.ToLower(a.card.Name), strings.ToLower(b.card.Name))
+	},
+	"id": func(a, b cardRow) int { return a.card.IDShort - b.card.IDShort },
+}
+
+// parseSort parses --sort: comma-separated keys, each optionally followed
+// by :desc or :asc.
+func parseSort(spec string) ([]sortKey, error) {
+	var keys []sortKey
+	for _, part := range splitList(spec) {
+		name, dir, _ := strings.Cut(strings.ToLower(part), ":")
+		if _, ok := rowComparators[name]; !ok {
+			return nil, fmt.Errorf("unknown sort key %q (available: %s)", name, sortKeyNames)
+		}
+		switch dir {
+		case "", "asc", "desc":
+		default:
+			return nil, fmt.Errorf("unknown sort direction %q in %q; use :asc or :desc", dir, part)
+		}
+		keys = append(keys, sortKey{name: name, desc: dir == "desc"})
+	}
+	return keys, nil
+}
+
+// sortRows orders rows by keys, falling back to the card number. Cards
+// without a due date or activity sort last in either direction.
+func sortRows(rows []cardRow, keys []sortKey) {
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		for _, key := range keys {
+			if key.name == "due" || key.name == "activity" {
+				// Missing dates go last regardless of direction
+				aMissing, bMissing := a.missing(key.name), b.missing(key.name)
+				if aMissing != bMissing {
+					return bMissing
+				}
+			}
+
+			c := rowComparators[key.name](a, b)
+			if key.desc {
+				c = -c
+			}
+			if c != 0 {
+				return c < 0
+			}
+		}
+		return a.card.IDShort < b.card.IDShort
+	})
+}
+
+func (row cardRow) missing(key string) bool {
+	if key == "due" {
+		return rowDue(row).IsZero()
+	}
+	return rowActivity(row).IsZero()
+}
+
+func rowDue(row cardRow) time.Time {
+	due, _ := time.Parse(time.RFC3339, row.card.Due)
+	return due
+}
+
+func rowActivity(row cardRow) time.Time {
+	at, _ := time.Parse(time.RFC3339, row.card.DateLastActivity)
+	return at
+}
+
+func compareFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareTime(a, b time.Time) int {
+	return a.Compare(b)
+}
+
+// formatDate shows a date compactly, with the year only when it isn't this
+// year.
+func formatDate(t time.Time) string {
+	t = t.Local()
+	if t.Year() == time.Now().Year() {
+		return t.Format("Jan 2")
+	}
+	return t.Format("Jan 2 2006")
+}
+
+// compactAge shortens a duration for table cells: 45m, 5h, 3d, 6w, 2y.
+func compactAge(d time.Duration) string {
+	switch {
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d/time.Minute))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d/time.Hour))
+	case d < 14*24*time.Hour:
+		return fmt.Sprintf("%dd", int(d/(24*time.Hour)))
+	case d < 365*24*time.Hour:
+		return fmt.Sprintf("%dw", int(d/(7*24*time.Hour)))
+	}
+	return fmt.Sprintf("%dy", int(d/(365*24*time.Hour)))
+}
+
+// resolveRowMembers fills in the members of each row's card, for the members
+// column and member comparisons in --where.
+func resolveRowMembers(rows []cardRow, client *trello.Client) {
+	for i := range rows {
+		if len(rows[i].card.IDMembers) > 0 {
+			rows[i].members, _ = client.ResolveMembers(rows[i].card.IDBoard, rows[i].card.IDMembers)
+		}
+	}
+}
@@ -2,48 +2,244 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
+// CurrentVersion is the schema version written by this build. Bump it and
+// append a migration whenever the on-disk format changes.
+const CurrentVersion = 6
+
 type Config struct {
+	Version   int    `json:"version"`
 	APIKey    string `json:"api_key"`
 	APIToken  string `json:"api_token"`
 	Workspace string `json:"workspace"`
 	BoardID   string `json:"board_id"`
+
+	// BranchTemplate is a text/template for branch names made from cards;
+	// empty means "{{.Number}}-{{.Slug}}"
+	BranchTemplate string `json:"branch_template,omitempty"`
+	// InProgressList is where 'branch --start' moves cards; empty means
+	// "In Progress"
+	InProgressList string `json:"in_progress_list,omitempty"`
+	// BranchPattern is a regular expression whose first group finds the card
+	// in the current git branch name; empty means `(?:^|/)(\d+)(?:-|$)`
+	BranchPattern string `json:"branch_pattern,omitempty"`
+	// PRTemplate is the path of a text/template file for 'pr-body'; empty
+	// means the built-in template
+	PRTemplate string `json:"pr_template,omitempty"`
+
+	// Views are named listings, invoked with 'trello_cli view <name>'
+	Views map[string]View `json:"views,omitempty"`
+
+	// WIPLimits caps the number of cards per list name, overriding limits
+	// set on the lists in Trello
+	WIPLimits map[string]int `json:"wip_limits,omitempty"`
 }
 
-const configDir = ".config/trello_cli"
+// WIPLimit returns the configured WIP limit for a list, matching its name
+// case-insensitively.
+func (c *Config) WIPLimit(listName string) (int, bool) {
+	for name, limit := range c.WIPLimits {
+		if strings.EqualFold(name, listName) {
+			return limit, true
+		}
+	}
+	return 0, false
+}
+
+// View is a saved set of listing flags.
+type View struct {
+	Args []string `json:"args"`
+}
+
+const appDir = "trello_cli"
 const configFile = "config.json"
 
-func getConfigPath() string {
-	homeDir, _ := os.UserHomeDir()
-	return filepath.Join(homeDir, configDir, configFile)
+// migrations[n] upgrades a raw config document from version n to n+1.
+var migrations = []func(raw map[string]interface{}) error{
+	// 0 -> 1: files written before versioning; the fields are unchanged
+	func(raw map[string]interface{}) error { return nil },
+	// 1 -> 2: adds saved views; the version bump keeps older builds, which
+	// would drop them on save, from rewriting the file
+	func(raw map[string]interface{}) error { return nil },
+	// 2 -> 3: adds WIP limits, bumped for the same reason
+	func(raw map[string]interface{}) error { return nil },
+	// 3 -> 4: adds the branch template and in-progress list
+	func(raw map[string]interface{}) error { return nil },
+	// 4 -> 5: adds the branch pattern
+	func(raw map[string]interface{}) error { return nil },
+	// 5 -> 6: adds the pull request template
+	func(raw map[string]interface{}) error { return nil },
 }
 
-func LoadConfig() (*Config, error) {
-	path := getConfigPath()
+// Keys lists the settings addressable through Get, Set and Unset.
+func Keys() []string {
+	return []string{"api_key", "api_token", "workspace", "board_id", "branch_template", "in_progress_list", "branch_pattern", "pr_template"}
+}
+
+func (c *Config) field(key string) (*string, error) {
+	switch key {
+	case "api_key":
+		return &c.APIKey, nil
+	case "api_token":
+		return &c.APIToken, nil
+	case "workspace":
+		return &c.Workspace, nil
+	case "board_id":
+		return &c.BoardID, nil
+	case "branch_template":
+		return &c.BranchTemplate, nil
+	case "in_progress_list":
+		return &c.InProgressList, nil
+	case "branch_pattern":
+		return &c.BranchPattern, nil
+	case "pr_template":
+		return &c.PRTemplate, nil
+	}
+	return nil, fmt.Errorf("unknown config key %q (valid keys: %s)", key, strings.Join(Keys(), ", "))
+}
+
+func (c *Config) Get(key string) (string, error) {
+	f, err := c.field(key)
+	if err != nil {
+		return "", err
+	}
+	return *f, nil
+}
+
+func (c *Config) Set(key, value string) error {
+	f, err := c.field(key)
+	if err != nil {
+		return err
+	}
+	*f = value
+	return nil
+}
+
+func (c *Config) Unset(key string) error {
+	return c.Set(key, "")
+}
+
+func configDir() (string, error) {
+	// Respect XDG_CONFIG_HOME when it is set to an absolute path
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" && filepath.IsAbs(xdg) {
+		return filepath.Join(xdg, appDir), nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot determine config directory: set HOME or XDG_CONFIG_HOME: %w", err)
+	}
+
+	return filepath.Join(homeDir, ".config", appDir), nil
+}
+
+// DataDir is where local data such as board snapshots is kept. It respects
+// XDG_DATA_HOME and defaults to ~/.local/share/trello_cli.
+func DataDir() (string, error) {
+	if xdg := os.Getenv("XDG_DATA_HOME"); xdg != "" && filepath.IsAbs(xdg) {
+		return filepath.Join(xdg, appDir), nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot determine data directory: set HOME or XDG_DATA_HOME: %w", err)
+	}
+
+	return filepath.Join(homeDir, ".local", "share", appDir), nil
+}
 
-	if _, err := os.Stat(path); os.IsNotExist(err) {
-		return &Config{}, nil
+func ConfigPath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, configFile), nil
+}
+
+func LoadConfig() (*Config, error) {
+	path, err := ConfigPath()
+	if err != nil {
+		return nil, err
 	}
 
 	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Config{Version: CurrentVersion}, nil
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
+	config, migrated, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Persist the upgraded document so the migration only runs once
+	if migrated {
+		if err := SaveConfig(config); err != nil {
+			return nil, fmt.Errorf("failed to save migrated config: %w", err)
+		}
+	}
+
+	return config, nil
+}
+
+// decode parses a config document, applying any pending migrations. The
+// returned bool reports whether the document was upgraded.
+func decode(data []byte) (*Config, bool, error) {
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, false, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	if raw == nil {
+		raw = map[string]interface{}{}
+	}
+
+	version := 0
+	if v, ok := raw["version"]; ok {
+		f, ok := v.(float64)
+		if !ok || f < 0 || f != float64(int(f)) {
+			return nil, false, fmt.Errorf("failed to parse config file: invalid version %v", v)
+		}
+		version = int(f)
+	}
+
+	if version > CurrentVersion {
+		return nil, false, fmt.Errorf("config file version %d is newer than this build supports (%d); please upgrade trello_cli", version, CurrentVersion)
+	}
+
+	for v := version; v < CurrentVersion; v++ {
+		if err := migrations[v](raw); err != nil {
+			return nil, false, fmt.Errorf("failed to migrate config from version %d to %d: %w", v, v+1, err)
+		}
+	}
+	raw["version"] = CurrentVersion
+
+	upgraded, err := json.Marshal(raw)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to marshal migrated config: %w", err)
+	}
+
 	var config Config
-	if err := json.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	if err := json.Unmarshal(upgraded, &config); err != nil {
+		return nil, false, fmt.Errorf("failed to parse config file: %w", err)
 	}
 
-	return &config, nil
+	return &config, version != CurrentVersion, nil
 }
 
 func SaveConfig(config *Config) error {
-	path := getConfigPath()
+	path, err := ConfigPath()
+	if err != nil {
+		return err
+	}
 
 	// Create directory if it doesn't exist
 	dir := filepath.Dir(path)
@@ -51,14 +247,67 @@ func SaveConfig(config *Config) error {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 
+	config.Version = CurrentVersion
 	data, err := json.MarshalIndent(config, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
 
-	if err := os.WriteFile(path, data, 0644); err != nil {
+	if err := WriteFileAtomic(path, data, 0600); err != nil {
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
 
 	return nil
 }
+
+// WriteFileAtomic writes data to a temporary file in the same directory and
+// renames it over path, so readers never observe a partially written file.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+
+	// Clean up the temp file on any failure before the rename
+	success := false
+	defer func() {
+		if !success {
+			tmp.Close()
+			os.Remove(tmpPath)
+		}
+	}()
+
+	if _, err := tmp.Write(data); err != nil {
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return err
+	}
+
+	success = true
+	return nil
+}
+
+// Reset removes the config file. A missing file is not an error.
+func Reset() error {
+	path, err := ConfigPath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove config file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,297 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+	"trello_cli/config"
+)
+
+const configCommands = `Usage: trello_cli config <command> [arguments]
+
+Commands:
+  get [key]          Print a config value, or all values when no key is given
+  set <key> <value>  Set a config value
+  unset <key>        Clear a config value
+  edit               Open the config file in $VISUAL or $EDITOR
+  reset [--yes]      Delete the config file, including credentials
+  switch-board       Select a different workspace and board, keeping credentials
+  doctor             Check credentials, board access and list visibility
+
+Keys: `
+
+// configUsage returns the usage text, listing the settable keys.
+func configUsage() string {
+	return configCommands + strings.Join(config.Keys(), ", ") + "\n"
+}
+
+func runConfigCommand(args []string) {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, configUsage())
+		os.Exit(2)
+	}
+
+	switch args[0] {
+	case "get":
+		configGet(args[1:])
+	case "set":
+		configSet(args[1:])
+	case "unset":
+		configUnset(args[1:])
+	case "edit":
+		configEdit()
+	case "reset":
+		configReset(args[1:])
+	case "switch-board":
+		configSwitchBoard()
+	case "doctor":
+		configDoctor()
+	case "help", "-h", "--help":
+		fmt.Print(configUsage())
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown config command: %s\n\n%s", args[0], configUsage())
+		os.Exit(2)
+	}
+}
+
+func configGet(args []string) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	if len(args) == 1 {
+		value, err := cfg.Get(args[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(value)
+		return
+	}
+	if len(args) > 1 {
+		log.Fatalf("Usage: trello_cli config get [key]")
+	}
+
+	// Print every key, masking the token so it doesn't end up in scrollback
+	for _, key := range config.Keys() {
+		value, _ := cfg.Get(key)
+		if key == "api_token" && value != "" {
+			value = maskSecret(value)
+		}
+		fmt.Printf("%s=%s\n", key, value)
+	}
+}
+
+func configSet(args []string) {
+	if len(args) != 2 {
+		log.Fatalf("Usage: trello_cli config set <key> <value>")
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	if err := cfg.Set(args[0], args[1]); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := config.SaveConfig(cfg); err != nil {
+		log.Fatalf("Failed to save config: %v", err)
+	}
+}
+
+func configUnset(args []string) {
+	if len(args) != 1 {
+		log.Fatalf("Usage: trello_cli config unset <key>")
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	if err := cfg.Unset(args[0]); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := config.SaveConfig(cfg); err != nil {
+		log.Fatalf("Failed to save config: %v", err)
+	}
+}
+
+func configEdit() {
+	// Make sure there is a file to edit, migrating an old one if needed
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	if err := config.SaveConfig(cfg); err != nil {
+		log.Fatalf("Failed to save config: %v", err)
+	}
+
+	path, err := config.ConfigPath()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	// The editor variable may carry arguments, e.g. "code --wait"
+	parts := strings.Fields(editor)
+	cmd := exec.Command(parts[0], append(parts[1:], path)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		log.Fatalf("Editor exited with error: %v", err)
+	}
+
+	// Validate the result so mistakes surface now rather than on the next run
+	if _, err := config.LoadConfig(); err != nil {
+		log.Fatalf("Config file is no longer valid: %v\nRun 'trello_cli config edit' again to fix it.", err)
+	}
+}
+
+func configReset(args []string) {
+	fs := flag.NewFlagSet("config reset", flag.ExitOnError)
+	yes := fs.Bool("yes", false, "Do not ask for confirmation")
+	fs.Parse(args)
+
+	if !*yes && !confirm("This deletes your config file, including API credentials. Continue?") {
+		fmt.Println("Aborted.")
+		return
+	}
+
+	if err := config.Reset(); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("Config reset. Run trello_cli to set it up again.")
+}
+
+func configSwitchBoard() {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	if cfg.APIKey == "" || cfg.APIToken == "" {
+		log.Fatalf("API credentials not found. Please run trello_cli without arguments first to set up credentials.")
+	}
+
+	// Boards may have been created since they were cached
+	clientFlags.refresh = true
+	client := newClient(cfg)
+	if err := selectBoard(client, cfg); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := config.SaveConfig(cfg); err != nil {
+		log.Fatalf("Failed to save config: %v", err)
+	}
+	fmt.Println("Board updated.")
+}
+
+func configDoctor() {
+	ok := true
+	pass := func(format string, a ...interface{}) {
+		fmt.Printf("✓ "+format+"\n", a...)
+	}
+	fail := func(format string, a ...interface{}) {
+		fmt.Printf("✗ "+format+"\n", a...)
+		ok = false
+	}
+	defer func() {
+		if !ok {
+			os.Exit(1)
+		}
+	}()
+
+	path, err := config.ConfigPath()
+	if err != nil {
+		fail("Config path: %v", err)
+		return
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		fail("Config file %s: %v", path, err)
+		return
+	}
+	pass("Config file %s (version %d)", path, cfg.Version)
+
+	if cfg.APIKey == "" || cfg.APIToken == "" {
+		fail("API credentials missing (set api_key and api_token)")
+		return
+	}
+	pass("API credentials present")
+
+	// Check against Trello itself, not cached answers
+	clientFlags.noCache = true
+	client := newClient(cfg)
+	me, err := client.GetCurrentMember()
+	if err != nil {
+		fail("Token rejected by Trello: %v", err)
+		return
+	}
+	pass("Token valid for %s (@%s)", me.FullName, me.Username)
+
+	if cfg.Workspace == "" {
+		fail("No workspace selected (run 'trello_cli config switch-board')")
+	} else {
+		pass("Workspace %s", cfg.Workspace)
+	}
+
+	if cfg.BoardID == "" {
+		fail("No board selected (run 'trello_cli config switch-board')")
+		return
+	}
+
+	board, err := client.GetBoard(cfg.BoardID)
+	if err != nil {
+		fail("Board %s not accessible: %v", cfg.BoardID, err)
+		return
+	}
+	if board.Closed {
+		fail("Board %q is closed", board.Name)
+	} else {
+		pass("Board %q accessible", board.Name)
+	}
+
+	lists, err := client.GetLists(cfg.BoardID)
+	if err != nil {
+		fail("Lists not readable: %v", err)
+		return
+	}
+	if len(lists) == 0 {
+		fail("Board %q has no visible lists", board.Name)
+		return
+	}
+	pass("%d lists visible", len(lists))
+}
+
+// confirm asks a yes/no question on stdin and defaults to no.
+func confirm(question string) bool {
+	fmt.Printf("%s [y/N] ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// maskSecret keeps the last four characters of a secret for recognition.
+func maskSecret(secret string) string {
+	if len(secret) <= 4 {
+		return strings.Repeat("*", len(secret))
+	}
+	return strings.Repeat("*", len(secret)-4) + secret[len(secret)-4:]
+}
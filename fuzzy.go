@@ -0,0 +1,91 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+type fuzzyMatch struct {
+	index     int   // index of the candidate in the input slice
+	score     int   // higher is better
+	positions []int // rune offsets of matched characters, for highlighting
+}
+
+// fuzzyScore reports whether every rune of pattern appears in text in order,
+// ignoring case, and scores the match. Consecutive runs and matches at word
+// starts score higher, so "ip" prefers "In Progress" over "Shipped".
+func fuzzyScore(pattern, text string) (int, []int, bool) {
+	if pattern == "" {
+		return 0, nil, true
+	}
+
+	p := []rune(strings.ToLower(pattern))
+	t := []rune(text)
+	positions := make([]int, 0, len(p))
+	score := 0
+	pi := 0
+	prev := -2
+
+	for ti := 0; ti < len(t) && pi < len(p); ti++ {
+		if unicode.ToLower(t[ti]) != p[pi] {
+			continue
+		}
+
+		score++
+		if ti == prev+1 {
+			score += 5
+		}
+		if ti == 0 || !unicode.IsLetter(t[ti-1]) && !unicode.IsDigit(t[ti-1]) {
+			score += 8
+		} else if unicode.IsUpper(t[ti]) && unicode.IsLower(t[ti-1]) {
+			score += 4
+		}
+
+		positions = append(positions, ti)
+		prev = ti
+		pi++
+	}
+
+	if pi < len(p) {
+		return 0, nil, false
+	}
+
+	// Prefer tighter and earlier matches
+	score -= positions[len(positions)-1] - positions[0] - len(positions) + 1
+	score -= positions[0] / 4
+
+	return score, positions, true
+}
+
+// fuzzyFilter matches pattern against every candidate and returns the hits
+// ordered by score. Ties keep the input order. Whitespace in the pattern
+// separates terms that must all match, in any order.
+func fuzzyFilter(pattern string, candidates []string) []fuzzyMatch {
+	terms := strings.Fields(pattern)
+	var matches []fuzzyMatch
+
+	for i, candidate := range candidates {
+		m := fuzzyMatch{index: i}
+		ok := true
+		for _, term := range terms {
+			score, positions, matched := fuzzyScore(term, candidate)
+			if !matched {
+				ok = false
+				break
+			}
+			m.score += score
+			m.positions = append(m.positions, positions...)
+		}
+		if ok {
+			sort.Ints(m.positions)
+			matches = append(matches, m)
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+
+	return matches
+}
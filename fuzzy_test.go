@@ -0,0 +1,98 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFuzzyScore(t *testing.T) {
+	tests := []struct {
+		pattern   string
+		text      string
+		ok        bool
+		positions []int
+	}{
+		{"", "anything", true, nil},
+		{"login", "Fix login page", true, []int{4, 5, 6, 7, 8}},
+		{"LOGIN", "fix Login page", true, []int{4, 5, 6, 7, 8}},
+		{"flp", "Fix login page", true, []int{0, 4, 10}},
+		{"pl", "Fix login page", false, nil},
+		{"xyz", "Fix login page", false, nil},
+		{"café", "Le Café", true, []int{3, 4, 5, 6}},
+		{"日本", "日本語のカード", true, []int{0, 1}},
+		{"#12", "#123 Release", true, []int{0, 1, 2}},
+	}
+
+	for _, tt := range tests {
+		_, positions, ok := fuzzyScore(tt.pattern, tt.text)
+		if ok != tt.ok || !reflect.DeepEqual(positions, tt.positions) {
+			t.Errorf("fuzzyScore(%q, %q) = %v, %v; want %v, %v", tt.pattern, tt.text, positions, ok, tt.positions, tt.ok)
+		}
+	}
+}
+
+func TestFuzzyScoreRanking(t *testing.T) {
+	// Each pattern should score the better text higher than the worse one
+	tests := []struct {
+		name    string
+		pattern string
+		better  string
+		worse   string
+	}{
+		{"word starts", "ip", "In Progress", "Shipped"},
+		{"consecutive runs", "log", "blog", "belong"},
+		{"tighter matches", "fl", "Fix the login", "F blah blah blah l"},
+		{"earlier matches", "done", "done today", "not quite done"},
+		{"camel case humps", "gc", "getCards", "gzipcat"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			better, _, okBetter := fuzzyScore(tt.pattern, tt.better)
+			worse, _, okWorse := fuzzyScore(tt.pattern, tt.worse)
+			if !okBetter || !okWorse {
+				t.Fatalf("%q should match both %q and %q", tt.pattern, tt.better, tt.worse)
+			}
+			if better <= worse {
+				t.Errorf("%q scored %d on %q, not above %d on %q", tt.pattern, better, tt.better, worse, tt.worse)
+			}
+		})
+	}
+}
+
+func TestFuzzyFilter(t *testing.T) {
+	candidates := []string{"Shipped", "In Progress", "Done", "Inbox"}
+
+	tests := []struct {
+		pattern string
+		want    []int // candidate indices, best first
+	}{
+		{"", []int{0, 1, 2, 3}},
+		{"ip", []int{1, 0}},
+		{"in", []int{1, 3}},
+		{"zzz", nil},
+		{"prog in", []int{1}},
+		{"in prog", []int{1}},
+		{"in zzz", nil},
+	}
+
+	for _, tt := range tests {
+		var got []int
+		for _, m := range fuzzyFilter(tt.pattern, candidates) {
+			got = append(got, m.index)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("fuzzyFilter(%q) = %v, want %v", tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestFuzzyFilterPositions(t *testing.T) {
+	matches := fuzzyFilter("prog in", []string{"In Progress"})
+	if len(matches) != 1 {
+		t.Fatalf("got %d matches, want 1", len(matches))
+	}
+	if want := []int{0, 1, 3, 4, 5, 6}; !reflect.DeepEqual(matches[0].positions, want) {
+		t.Errorf("positions = %v, want %v", matches[0].positions, want)
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// errNotGitRepo is returned when the working directory is not inside a git
+// repository.
+var errNotGitRepo = errors.New("not inside a git repository")
+
+// gitOutput runs git with args and returns its trimmed stdout. Git's own
+// error message is included when it fails.
+func gitOutput(args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// gitRun runs git with args, letting it talk to the terminal directly.
+func gitRun(args ...string) error {
+	cmd := exec.Command("git", args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("git %s failed: %w", args[0], err)
+	}
+	return nil
+}
+
+// checkGitRepo reports errNotGitRepo unless the working directory is inside
+// a git work tree.
+func checkGitRepo() error {
+	if _, err := exec.LookPath("git"); err != nil {
+		return fmt.Errorf("git is not installed or not on PATH")
+	}
+	if out, err := gitOutput("rev-parse", "--is-inside-work-tree"); err != nil || out != "true" {
+		return errNotGitRepo
+	}
+	return nil
+}
+
+// gitBranchExists reports whether a local branch named name exists.
+func gitBranchExists(name string) bool {
+	_, err := gitOutput("rev-parse", "--verify", "--quiet", "refs/heads/"+name)
+	return err == nil
+}
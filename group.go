@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"trello_cli/config"
+	"trello_cli/trello"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	groupHeaderStyle = lipgloss.NewStyle().Bold(true)
+	wipFullStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("3")) // Yellow color
+	wipOverStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("1")).Bold(true)
+)
+
+// listGroup is one list of the grouped listing with the cards shown in it.
+type listGroup struct {
+	boardName string // set when listing several boards
+	list      trello.List
+	total     int // open cards in the list, shown or not; -1 when unknown
+	rows      []cardRow
+}
+
+// groupRows puts rows under their lists, keeping the order of rows within
+// each list. When the board's lists and cards are known, every list appears
+// in board order, empty or not, and its total counts every card in it.
+// Otherwise groups are made from the rows alone.
+func groupRows(rows []cardRow, lists []trello.List, cards []trello.Card, allowedLists map[string]bool) []listGroup {
+	var groups []*listGroup
+	byList := make(map[string]*listGroup)
+
+	if lists != nil {
+		totals := make(map[string]int)
+		for _, card := range cards {
+			totals[card.IDList]++
+		}
+		for _, list := range lists {
+			if allowedLists != nil && !allowedLists[strings.ToLower(list.Name)] {
+				continue
+			}
+			group := &listGroup{list: list, total: totals[list.ID]}
+			groups = append(groups, group)
+			byList[list.ID] = group
+		}
+	}
+
+	for _, row := range rows {
+		group, ok := byList[row.card.IDList]
+		if !ok {
+			group = &listGroup{
+				boardName: row.boardName,
+				list:      trello.List{ID: row.card.IDList, Name: row.listName, Pos: row.listPos},
+				total:     -1,
+			}
+			groups = append(groups, group)
+			byList[row.card.IDList] = group
+		}
+		group.rows = append(group.rows, row)
+	}
+
+	// Lists from the board are already in order; the rest go by board and
+	// position, which puts lists missing from the board last
+	sorted := make([]listGroup, len(groups))
+	for i, group := range groups {
+		sorted[i] = *group
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].boardName != sorted[j].boardName {
+			return sorted[i].boardName < sorted[j].boardName
+		}
+		return sorted[i].list.Pos < sorted[j].list.Pos
+	})
+	return sorted
+}
+
+// wipLimit returns the WIP limit for a list: the configured one if any,
+// otherwise the limit set on the list in Trello.
+func wipLimit(cfg *config.Config, list trello.List) (int, bool) {
+	if limit, ok := cfg.WIPLimit(list.Name); ok {
+		return limit, limit > 0
+	}
+	limit, err := strconv.Atoi(list.SoftLimit.String())
+	return limit, err == nil && limit > 0
+}
+
+// groupHeader renders a list's header: its name, the number of cards shown,
+// and how full it is against its WIP limit.
+func groupHeader(cfg *config.Config, group listGroup) string {
+	name := group.list.Name
+	if group.boardName != "" {
+		name = group.boardName + " / " + name
+	}
+	header := groupHeaderStyle.Render(name) + " " + dimStyle.Render(fmt.Sprintf("(%d)", len(group.rows)))
+
+	if limit, ok := wipLimit(cfg, group.list); ok && group.total >= 0 {
+		wip := fmt.Sprintf("WIP %d/%d", group.total, limit)
+		switch {
+		case group.total > limit:
+			header += " " + wipOverStyle.Render(wip+" over limit")
+		case group.total == limit:
+			header += " " + wipFullStyle.Render(wip)
+		default:
+			header += " " + dimStyle.Render(wip)
+		}
+	}
+	return header
+}
+
+// printGroups prints the grouped listing table. With collapseEmpty, lists
+// without cards to show are named on one line at the end instead of getting
+// a header each.
+func printGroups(cfg *config.Config, groups []listGroup, columns []listColumn, collapseEmpty bool) {
+	t := newRowTable(columns)
+	var empty []string
+	for _, group := range groups {
+		if collapseEmpty && len(group.rows) == 0 {
+			name := group.list.Name
+			if group.boardName != "" {
+				name = group.boardName + " / " + name
+			}
+			empty = append(empty, name)
+			continue
+		}
+		t.groups = append(t.groups, tableGroup{header: groupHeader(cfg, group), rows: rowCells(group.rows, columns)})
+	}
+	t.render(os.Stdout, terminalWidth())
+
+	if len(empty) > 0 {
+		if len(t.groups) > 0 {
+			fmt.Println()
+		}
+		fmt.Println(dimStyle.Render("Empty: " + strings.Join(empty, ", ")))
+	}
+}
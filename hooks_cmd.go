@@ -0,0 +1,343 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"trello_cli/config"
+	"trello_cli/store"
+	"trello_cli/trello"
+)
+
+const hooksUsage = `Usage: trello_cli hooks <command>
+
+Commands:
+  install     Install git hooks in the current repository that link commits and cards
+  uninstall   Remove the hooks installed by 'hooks install'
+
+The prepare-commit-msg hook adds a "Trello: <card link>" trailer for the card
+of the current branch. The post-commit hook comments on the card with the
+commit hash and subject. Turn both off for one repository with:
+
+  git config trello.hooks false
+`
+
+// hookNames are the git hooks 'hooks install' manages.
+var hookNames = []string{"prepare-commit-msg", "post-commit"}
+
+// hookMarker identifies hook scripts written by 'hooks install', so other
+// hooks are never overwritten or removed.
+const hookMarker = "# Installed by trello_cli hooks install"
+
+// trailerKey is the commit message trailer linking a commit to its card.
+const trailerKey = "Trello"
+
+func runHooksCommand(args []string) {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, hooksUsage)
+		os.Exit(2)
+	}
+
+	switch args[0] {
+	case "install":
+		hooksInstall()
+	case "uninstall":
+		hooksUninstall()
+	case "run":
+		// Called by the installed hooks, never fails so commits aren't blocked
+		if err := runHook(args[1:]); err != nil {
+			fmt.Fprintf(os.Stderr, "trello_cli: %v\n", err)
+		}
+	case "help", "-h", "--help":
+		fmt.Print(hooksUsage)
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown hooks command: %s\n\n%s", args[0], hooksUsage)
+		os.Exit(2)
+	}
+}
+
+// hooksDir returns the repository's hooks directory, honoring core.hooksPath.
+func hooksDir() (string, error) {
+	if err := checkGitRepo(); err != nil {
+		return "", err
+	}
+	dir, err := gitOutput("rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return "", err
+	}
+	return filepath.Abs(dir)
+}
+
+// hookScript is the script installed for hook, running this executable.
+func hookScript(exe, hook string) string {
+	return fmt.Sprintf("#!/bin/sh\n%s; remove with 'trello_cli hooks uninstall'.\n%s hooks run %s \"$@\" || true\n",
+		hookMarker, shellQuote(exe), hook)
+}
+
+func hooksInstall() {
+	dir, err := hooksDir()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Point the hooks at this binary so they work without it on PATH
+	exe, err := os.Executable()
+	if err == nil {
+		exe, err = filepath.EvalSymlinks(exe)
+	}
+	if err != nil {
+		exe = "trello_cli"
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		log.Fatalf("Failed to create %s: %v", dir, err)
+	}
+
+	failed := false
+	for _, hook := range hookNames {
+		path := filepath.Join(dir, hook)
+		existing, err := os.ReadFile(path)
+		if err == nil && !strings.Contains(string(existing), hookMarker) {
+			fmt.Fprintf(os.Stderr, "%s already exists and was not installed by trello_cli; leaving it alone.\n", path)
+			fmt.Fprintf(os.Stderr, "To use both, add this line to it:\n  %s hooks run %s \"$@\" || true\n", shellQuote(exe), hook)
+			failed = true
+			continue
+		}
+
+		if err := os.WriteFile(path, []byte(hookScript(exe, hook)), 0755); err != nil {
+			log.Fatalf("Failed to write %s: %v", path, err)
+		}
+		fmt.Printf("Installed %s\n", path)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func hooksUninstall() {
+	dir, err := hooksDir()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	removed := 0
+	for _, hook := range hookNames {
+		path := filepath.Join(dir, hook)
+		existing, err := os.ReadFile(path)
+		if err != nil || !strings.Contains(string(existing), hookMarker) {
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			log.Fatalf("Failed to remove %s: %v", path, err)
+		}
+		fmt.Printf("Removed %s\n", path)
+		removed++
+	}
+	if removed == 0 {
+		fmt.Println("No trello_cli hooks installed in this repository.")
+	}
+}
+
+// runHook runs the named hook with git's arguments.
+func runHook(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: trello_cli hooks run <hook> [args]")
+	}
+
+	// Repositories opt out with 'git config trello.hooks false'
+	if enabled, _ := gitOutput("config", "--type=bool", "--get", "trello.hooks"); enabled == "false" {
+		return nil
+	}
+
+	switch args[0] {
+	case "prepare-commit-msg":
+		if len(args) < 2 {
+			return fmt.Errorf("prepare-commit-msg: missing message file")
+		}
+		source := ""
+		if len(args) > 2 {
+			source = args[2]
+		}
+		return prepareCommitMsg(args[1], source)
+	case "post-commit":
+		return postCommit()
+	}
+	return fmt.Errorf("unknown hook %q", args[0])
+}
+
+// prepareCommitMsg adds a trailer linking the commit to the branch's card.
+func prepareCommitMsg(file, source string) error {
+	// Merges and squashes describe other commits, which carry their own links
+	if source == "merge" || source == "squash" {
+		return nil
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return err
+	}
+	ref, _, err := branchCardRef(cfg)
+	if err != nil || ref == "" {
+		return err
+	}
+
+	shortLink, err := hookShortLink(cfg, ref)
+	if err != nil {
+		return fmt.Errorf("no Trello trailer added: %w", err)
+	}
+
+	trailer := fmt.Sprintf("%s: %s", trailerKey, shortCardURL(shortLink))
+	_, err = gitOutput("interpret-trailers", "--in-place", "--if-exists", "addIfDifferent", "--trailer", trailer, file)
+	return err
+}
+
+// postCommit comments on the cards the commit links to with its hash and
+// subject. Without a trailer it falls back to the branch's card.
+func postCommit() error {
+	// Rebases and cherry-picks replay commits that were already reported
+	for _, state := range []string{"rebase-merge", "rebase-apply", "CHERRY_PICK_HEAD"} {
+		if path, err := gitOutput("rev-parse", "--git-path", state); err == nil {
+			if _, err := os.Stat(path); err == nil {
+				return nil
+			}
+		}
+	}
+
+	// The commit being amended was reported already
+	if action, _ := gitOutput("reflog", "-1", "--format=%gs"); strings.HasPrefix(action, "commit (amend)") {
+		return nil
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return err
+	}
+
+	out, err := gitOutput("log", "-1", "--format=%h%n%s%n%(trailers:key="+trailerKey+",valueonly,separator=%x2C)")
+	if err != nil {
+		return err
+	}
+	lines := strings.SplitN(out+"\n\n", "\n", 3)
+	hash, subject := lines[0], lines[1]
+
+	refs := splitList(lines[2])
+	branch := ""
+	if len(refs) == 0 {
+		var ref string
+		if ref, branch, err = branchCardRef(cfg); err != nil || ref == "" {
+			return err
+		}
+		refs = []string{ref}
+	} else {
+		branch, _ = gitOutput("symbolic-ref", "--quiet", "--short", "HEAD")
+	}
+
+	text := fmt.Sprintf("Commit %s: %s", hash, subject)
+	if branch != "" {
+		text = fmt.Sprintf("Commit %s on %s: %s", hash, branch, subject)
+	}
+
+	var errs []error
+	for _, ref := range refs {
+		if err := commentFromHook(cfg, ref, text); err != nil {
+			errs = append(errs, fmt.Errorf("commenting on %s: %w", ref, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// commentFromHook posts text on the card ref refers to, queuing it when
+// Trello can't be reached or other changes are still queued.
+func commentFromHook(cfg *config.Config, ref, text string) error {
+	card, client, err := hookCard(cfg, ref)
+	if err != nil {
+		return err
+	}
+
+	op := store.Op{
+		Kind:      store.OpComment,
+		BoardID:   cfg.BoardID,
+		CardID:    card.ID,
+		CardShort: card.IDShort,
+		CardName:  card.Name,
+		Text:      text,
+	}
+
+	// Keep the queue's order rather than jumping ahead of it
+	queued, err := store.LoadQueue()
+	if err != nil {
+		return err
+	}
+	if client != nil && len(queued) == 0 {
+		_, err := applyOp(client, op)
+		if err == nil {
+			fmt.Fprintf(os.Stderr, "Trello: commented on #%d %s\n", card.IDShort, card.Name)
+			return nil
+		}
+		if !canQueue(op, err) {
+			return unknownOutcome(err)
+		}
+	}
+
+	if err := store.Enqueue(&op); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Trello: queued a comment on #%d; send it with 'trello_cli queue push'\n", card.IDShort)
+	return nil
+}
+
+// hookTimeout bounds each Trello request made from a hook, so a slow or
+// unreachable network delays a commit by seconds at most.
+const hookTimeout = 5 * time.Second
+
+// hookShortLink returns the short link of the card ref refers to for the
+// commit trailer. Links and short links need no lookup; card numbers are
+// looked up in the offline snapshot before asking Trello.
+func hookShortLink(cfg *config.Config, ref string) (string, error) {
+	if m := cardURLPattern.FindStringSubmatch(ref); m != nil {
+		return m[1], nil
+	}
+	if shortLinkPattern.MatchString(ref) {
+		return ref, nil
+	}
+
+	if snap, err := store.LoadSnapshot(cfg.BoardID); err == nil {
+		if card, err := lookupCard(snap, cfg.BoardID, ref); err == nil {
+			return card.ShortLink, nil
+		}
+	}
+
+	card, _, err := hookCard(cfg, ref)
+	if err != nil {
+		return "", err
+	}
+	return card.ShortLink, nil
+}
+
+// hookCard looks up the card for a hook, falling back to the offline
+// snapshot when Trello can't be reached. The client is nil in that case.
+// Only card numbers, links, short links and IDs are accepted: a hook must
+// never search titles, which could prompt.
+func hookCard(cfg *config.Config, ref string) (*trello.DetailedCard, *trello.Client, error) {
+	if cfg.APIKey == "" || cfg.APIToken == "" || cfg.BoardID == "" {
+		return nil, nil, fmt.Errorf("trello_cli is not set up; run it once to log in and choose a board")
+	}
+
+	client := newClient(cfg)
+	client.SetTimeout(hookTimeout)
+	card, err := lookupCard(client, cfg.BoardID, ref)
+	if err == nil || !isNetworkError(err) {
+		return card, client, err
+	}
+
+	snap, serr := store.LoadSnapshot(cfg.BoardID)
+	if serr != nil {
+		return nil, nil, err
+	}
+	card, err = lookupCard(snap, cfg.BoardID, ref)
+	return card, nil, err
+}
@@ -0,0 +1,348 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"trello_cli/config"
+	"trello_cli/query"
+	"trello_cli/trello"
+)
+
+// boardFetchWorkers bounds concurrent per-board requests when listing
+// several boards, keeping well inside Trello's rate limits.
+const boardFetchWorkers = 4
+
+// cardRow is one line of the card listing.
+type cardRow struct {
+	card      trello.Card
+	listName  string
+	listPos   float64
+	boardName string // set when listing several boards
+
+	// members of the card by ID, filled in by resolveRowMembers when needed
+	members map[string]trello.Member
+}
+
+// collectRows turns a board's cards into listing rows. Unless all is set only
+// cards assigned to userID are kept; allowedLists, when non-nil, holds the
+// lower-cased names of the lists to include.
+func collectRows(cards []trello.Card, lists []trello.List, boardName, userID string, all bool, allowedLists map[string]bool) []cardRow {
+	// Create a map of list ID to list for quick lookup
+	listMap := make(map[string]trello.List)
+	for _, list := range lists {
+		listMap[list.ID] = list
+	}
+
+	var rows []cardRow
+	for _, card := range cards {
+		if !all && !containsString(card.IDMembers, userID) {
+			continue
+		}
+
+		list, ok := listMap[card.IDList]
+		listName := list.Name
+		if listName == "" {
+			listName = "Unknown"
+		}
+		if !ok {
+			// Lists missing from the board go after all of its lists
+			list.Pos = math.Inf(1)
+		}
+		if allowedLists != nil && !allowedLists[strings.ToLower(listName)] {
+			continue
+		}
+
+		rows = append(rows, cardRow{card: card, listName: listName, listPos: list.Pos, boardName: boardName})
+	}
+	return rows
+}
+
+// filterRows keeps the rows matching a --where query. Members are matched by
+// name only when resolveRowMembers has run; @me matches on userID.
+func filterRows(rows []cardRow, q *query.Query, userID string) []cardRow {
+	env := &query.Env{Now: time.Now(), Me: userID}
+
+	var kept []cardRow
+	for _, row := range rows {
+		card := query.Card{
+			Number:  row.card.IDShort,
+			Title:   row.card.Name,
+			Desc:    row.card.Desc,
+			List:    row.listName,
+			Board:   row.boardName,
+			Members: row.card.IDMembers,
+			Created: cardCreatedAt(row.card.ID),
+		}
+		for _, label := range row.card.Labels {
+			card.Labels = append(card.Labels, label.Name, label.Color)
+		}
+		if due, err := time.Parse(time.RFC3339, row.card.Due); err == nil {
+			card.Due = due
+		}
+
+		if len(row.members) > 0 {
+			card.Members = append([]string(nil), row.card.IDMembers...)
+			for _, member := range row.members {
+				card.Members = append(card.Members, member.Username, member.FullName)
+			}
+		}
+
+		if q.Match(&card, env) {
+			kept = append(kept, row)
+		}
+	}
+	return kept
+}
+
+// outputFormats lists the values accepted by --output.
+const outputFormats = "table, json, tsv"
+
+// checkOutputFormat validates --output before any requests are made.
+func checkOutputFormat(format string) error {
+	switch format {
+	case "table", "json", "tsv":
+		return nil
+	}
+	return fmt.Errorf("unknown output format %q (available: %s)", format, outputFormats)
+}
+
+// writeRows renders rows in the given output format. Only the table is
+// styled; json and tsv are meant for scripts. JSON always has every field,
+// so columns only apply to the table and tsv.
+func writeRows(rows []cardRow, format string, columns []listColumn) error {
+	if err := checkOutputFormat(format); err != nil {
+		return err
+	}
+
+	switch format {
+	case "json":
+		return printRowsJSON(rows)
+	case "tsv":
+		printRowsTSV(rows, columns)
+	default:
+		printRows(rows, columns)
+	}
+	return nil
+}
+
+type cardRowJSON struct {
+	ID           string   `json:"id"`
+	Number       int      `json:"number"`
+	Title        string   `json:"title"`
+	List         string   `json:"list"`
+	Board        string   `json:"board,omitempty"`
+	Labels       []string `json:"labels"`
+	Due          string   `json:"due,omitempty"`
+	LastActivity string   `json:"last_activity,omitempty"`
+	Comments     int      `json:"comments"`
+	URL          string   `json:"url"`
+}
+
+func printRowsJSON(rows []cardRow) error {
+	out := make([]cardRowJSON, len(rows))
+	for i, row := range rows {
+		labels := cardLabelNames(row.card)
+		if labels == nil {
+			labels = []string{}
+		}
+		out[i] = cardRowJSON{
+			ID:           row.card.ID,
+			Number:       row.card.IDShort,
+			Title:        row.card.Name,
+			List:         row.listName,
+			Board:        row.boardName,
+			Labels:       labels,
+			Due:          row.card.Due,
+			URL:          cardURL(row.card),
+			LastActivity: row.card.DateLastActivity,
+			Comments:     row.card.Badges.Comments,
+		}
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// printRowsTSV prints one card per line with the chosen columns.
+func printRowsTSV(rows []cardRow, columns []listColumn) {
+	clean := strings.NewReplacer("\t", " ", "\n", " ", "\r", " ")
+	for _, row := range rows {
+		fields := make([]string, len(columns))
+		for i, column := range columns {
+			value := column.text
+			if column.raw != nil {
+				value = column.raw
+			}
+			fields[i] = clean.Replace(value(row))
+		}
+		fmt.Println(strings.Join(fields, "\t"))
+	}
+}
+
+// printRows prints the listing table, fitted to the terminal.
+func printRows(rows []cardRow, columns []listColumn) {
+	t := newRowTable(columns)
+	t.groups = []tableGroup{{rows: rowCells(rows, columns)}}
+	t.render(os.Stdout, terminalWidth())
+}
+
+// newRowTable returns an empty table laid out for columns.
+func newRowTable(columns []listColumn) *table {
+	t := &table{columns: make([]tableColumn, len(columns))}
+	for c, column := range columns {
+		t.columns[c] = column.layout
+	}
+	return t
+}
+
+// rowCells renders rows as styled table cells.
+func rowCells(rows []cardRow, columns []listColumn) [][]tableCell {
+	cells := make([][]tableCell, len(rows))
+	for r, row := range rows {
+		cells[r] = make([]tableCell, len(columns))
+		for c, column := range columns {
+			cells[r][c] = tableCell{text: column.text(row), style: plainStyle}
+			if column.style != nil {
+				cells[r][c].style = column.style(row)
+			}
+		}
+	}
+	return cells
+}
+
+// forEachBoard calls fn for every board with bounded concurrency and waits
+// for all calls to finish.
+func forEachBoard(boards []trello.Board, fn func(board trello.Board)) {
+	jobs := make(chan trello.Board)
+	var wg sync.WaitGroup
+
+	workers := boardFetchWorkers
+	if len(boards) < workers {
+		workers = len(boards)
+	}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for board := range jobs {
+				fn(board)
+			}
+		}()
+	}
+
+	for _, board := range boards {
+		jobs <- board
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// workspaceBoards returns the open boards of the configured workspace,
+// narrowed to names (board names or IDs) when any are given.
+func workspaceBoards(client *trello.Client, cfg *config.Config, names []string) ([]trello.Board, error) {
+	if cfg.Workspace == "" {
+		return nil, fmt.Errorf("no workspace selected; run 'trello_cli config switch-board' to choose one")
+	}
+
+	boards, err := client.GetBoards(cfg.Workspace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get boards: %w", err)
+	}
+	if len(names) == 0 {
+		return boards, nil
+	}
+
+	var selected []trello.Board
+	for _, name := range names {
+		found := false
+		for _, board := range boards {
+			if board.ID == name || strings.EqualFold(board.Name, name) {
+				selected = append(selected, board)
+				found = true
+				break
+			}
+		}
+		if !found {
+			available := make([]string, len(boards))
+			for i, board := range boards {
+				available[i] = board.Name
+			}
+			return nil, fmt.Errorf("no open board named %q in this workspace (available: %s)", name, strings.Join(available, ", "))
+		}
+	}
+	return selected, nil
+}
+
+// boardRows fetches the cards and lists of each board concurrently. Boards
+// that fail are reported on stderr and left out rather than failing the
+// whole listing.
+func boardRows(client *trello.Client, boards []trello.Board, userID string, all bool, allowedLists map[string]bool) []cardRow {
+	var (
+		mu   sync.Mutex
+		rows []cardRow
+	)
+
+	forEachBoard(boards, func(board trello.Board) {
+		cards, err := client.GetCards(board.ID)
+		if err == nil {
+			var lists []trello.List
+			if lists, err = client.GetLists(board.ID); err == nil {
+				boardRows := collectRows(cards, lists, board.Name, userID, all, allowedLists)
+				mu.Lock()
+				rows = append(rows, boardRows...)
+				mu.Unlock()
+				return
+			}
+		}
+		fmt.Fprintf(os.Stderr, "Warning: skipping board %s: %v\n", board.Name, err)
+	})
+
+	return rows
+}
+
+// memberRows lists the current member's cards on every open board in every
+// workspace, fetching the lists of the boards involved concurrently.
+func memberRows(client *trello.Client, userID string, allowedLists map[string]bool) ([]cardRow, error) {
+	cards, err := client.GetMemberCards()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cards: %w", err)
+	}
+	allBoards, err := client.GetMemberBoards()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get boards: %w", err)
+	}
+
+	cardsByBoard := make(map[string][]trello.Card)
+	for _, card := range cards {
+		cardsByBoard[card.IDBoard] = append(cardsByBoard[card.IDBoard], card)
+	}
+	var boards []trello.Board
+	for _, board := range allBoards {
+		if !board.Closed && len(cardsByBoard[board.ID]) > 0 {
+			boards = append(boards, board)
+		}
+	}
+
+	var (
+		mu   sync.Mutex
+		rows []cardRow
+	)
+	forEachBoard(boards, func(board trello.Board) {
+		lists, err := client.GetLists(board.ID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: skipping board %s: %v\n", board.Name, err)
+			return
+		}
+		boardRows := collectRows(cardsByBoard[board.ID], lists, board.Name, userID, false, allowedLists)
+		mu.Lock()
+		rows = append(rows, boardRows...)
+		mu.Unlock()
+	})
+	return rows, nil
+}
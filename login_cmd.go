@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+	"trello_cli/config"
+	"trello_cli/trello"
+)
+
+func runLoginCommand(args []string) {
+	fs := flag.NewFlagSet("login", flag.ExitOnError)
+	apiKey := fs.String("key", "", "Trello API key (defaults to the configured key)")
+	scope := fs.String("scope", "read,write", "Comma-separated scopes to request: read, write, account")
+	expiration := fs.String("expiration", "never", "Token lifetime: 1hour, 1day, 30days or never")
+	noBrowser := fs.Bool("no-browser", false, "Print the authorization URL instead of opening a browser")
+	timeout := fs.Duration("timeout", 5*time.Minute, "How long to wait for authorization")
+	authorizeURL := fs.String("authorize-url", os.Getenv("TRELLO_AUTHORIZE_URL"), "Authorization endpoint (for testing against a local stand-in)")
+	fs.Parse(args)
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	if *apiKey == "" {
+		*apiKey = cfg.APIKey
+	}
+	if *apiKey == "" {
+		fmt.Printf("Trello API key (from %s): ", appKeyURL)
+		line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		*apiKey = strings.TrimSpace(line)
+	}
+	if *apiKey == "" {
+		log.Fatalf("An API key is required. Get one at %s", appKeyURL)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	token, err := authorize(ctx, trello.AuthRequest{
+		APIKey:       *apiKey,
+		AppName:      "trello_cli",
+		Scope:        *scope,
+		Expiration:   *expiration,
+		AuthorizeURL: *authorizeURL,
+	}, !*noBrowser)
+	if err != nil {
+		log.Fatalf("Login failed: %v", err)
+	}
+
+	cfg.APIKey = *apiKey
+	cfg.APIToken = token
+	if err := config.SaveConfig(cfg); err != nil {
+		log.Fatalf("Failed to save config: %v", err)
+	}
+
+	// The token is already saved; a failed check here is only a warning
+	member, err := trello.NewClient(cfg.APIKey, cfg.APIToken).GetCurrentMember()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: token saved but could not be verified: %v\n", err)
+		return
+	}
+	fmt.Printf("Logged in as %s (@%s)\n", member.FullName, member.Username)
+}
+
+// authorize runs the browser authorization flow and returns the token.
+func authorize(ctx context.Context, req trello.AuthRequest, launch bool) (string, error) {
+	session, err := trello.StartAuthorization(req)
+	if err != nil {
+		return "", err
+	}
+
+	opened := false
+	if launch {
+		opened = openBrowser(session.URL) == nil
+	}
+	if opened {
+		fmt.Fprintln(os.Stderr, "Opened your browser to authorize trello_cli. If nothing happened, visit:")
+	} else {
+		fmt.Fprintln(os.Stderr, "Visit this URL to authorize trello_cli:")
+	}
+	fmt.Fprintf(os.Stderr, "\n  %s\n\nWaiting for authorization...\n", session.URL)
+
+	return session.Wait(ctx)
+}
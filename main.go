@@ -5,18 +5,15 @@ import (
 	"fmt"
 	"log"
 	"os"
-	"sort"
-	"strconv"
 	"strings"
-	"time"
 	"trello_cli/config"
+	"trello_cli/query"
 	"trello_cli/trello"
-
-	"github.com/charmbracelet/glamour"
-	"github.com/charmbracelet/lipgloss"
 )
 
-func showCardDetails(cardID int, fieldFilter string) {
+// mustLoadClient loads the config and returns a client for it, exiting when
+// credentials have not been set up yet.
+func mustLoadClient() (*config.Config, *trello.Client) {
 	// Load existing config
 	cfg, err := config.LoadConfig()
 	if err != nil {
@@ -25,275 +22,299 @@ func showCardDetails(cardID int, fieldFilter string) {
 
 	// Check if we have API credentials
 	if cfg.APIKey == "" || cfg.APIToken == "" {
-		log.Fatalf("API credentials not found. Please run without --card flag first to set up credentials.")
+		log.Fatalf("API credentials not found. Please run trello_cli without arguments first to set up credentials.")
 	}
 
-	// Create Trello client
-	client := trello.NewClient(cfg.APIKey, cfg.APIToken)
+	if cfg.BoardID == "" {
+		log.Fatalf("No board selected. Run 'trello_cli config switch-board' to choose one.")
+	}
 
-	// First, get all cards to find the one with matching ShortID
-	cards, err := client.GetCards(cfg.BoardID)
-	if err != nil {
-		log.Fatalf("Failed to get cards: %v", err)
+	return cfg, newClient(cfg)
+}
+
+// clientFlags holds the options shared by every command that talks to Trello.
+var clientFlags struct {
+	noCache bool
+	refresh bool
+}
+
+func addClientFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&clientFlags.noCache, "no-cache", false, "Bypass the local response cache")
+	fs.BoolVar(&clientFlags.refresh, "refresh", false, "Revalidate cached responses with Trello before using them")
+}
+
+// newClient creates a client for the configured credentials, backed by the
+// response cache unless --no-cache was given. Setting TRELLO_TRACE=1 logs
+// each API request and its timing to stderr.
+func newClient(cfg *config.Config) *trello.Client {
+	client := trello.NewClient(cfg.APIKey, cfg.APIToken)
+	if os.Getenv("TRELLO_TRACE") == "1" {
+		client.SetTrace(os.Stderr)
 	}
 
-	// Find the card with the matching ShortID
-	var targetCard *trello.Card
-	for _, card := range cards {
-		if card.IDShort == cardID {
-			targetCard = &card
-			break
+	if !clientFlags.noCache {
+		// Without a cache directory the CLI still works, just uncached
+		if dir, err := trello.DefaultCacheDir(); err == nil {
+			client.EnableCache(dir, clientFlags.refresh)
 		}
 	}
+	return client
+}
 
-	if targetCard == nil {
-		log.Fatalf("Card with ID #%d not found on this board", cardID)
-	}
+// selectBoard runs the interactive workspace and board selection and stores
+// the result in cfg. The caller is responsible for saving the config.
+func selectBoard(client *trello.Client, cfg *config.Config) error {
+	fmt.Println("Fetching available workspaces...")
 
-	// Get card details using the full card ID
-	detailedCard, err := client.GetCardDetails(targetCard.ID)
+	workspaceID, err := PromptForOrganization(client)
 	if err != nil {
-		log.Fatalf("Failed to get card details: %v", err)
+		return fmt.Errorf("failed to select workspace: %w", err)
 	}
 
-	// Get comments using the full card ID
-	comments, err := client.GetCardComments(targetCard.ID)
-	if err != nil {
-		log.Fatalf("Failed to get card comments: %v", err)
-	}
+	fmt.Println("Fetching available boards...")
 
-	// Get lists for list name lookup
-	lists, err := client.GetLists(cfg.BoardID)
+	boardID, err := PromptForBoard(client, workspaceID)
 	if err != nil {
-		log.Fatalf("Failed to get lists: %v", err)
+		return fmt.Errorf("failed to select board: %w", err)
 	}
 
-	listMap := make(map[string]string)
-	for _, list := range lists {
-		listMap[list.ID] = list.Name
-	}
+	cfg.Workspace = workspaceID
+	cfg.BoardID = boardID
+	return nil
+}
 
-	// Build markdown content
-	var markdown strings.Builder
+// mustSetUpClient returns a client for cfg, first walking the user through
+// entering credentials and choosing a board if either is missing.
+func mustSetUpClient(cfg *config.Config) *trello.Client {
+	// If API credentials are missing, prompt for them
+	if cfg.APIKey == "" || cfg.APIToken == "" {
+		fmt.Println("Please provide your Trello API credentials:")
+		apiKey, apiToken, _, err := PromptForConfig(cfg.APIKey, cfg.APIToken)
+		if err != nil {
+			log.Fatalf("Failed to get API credentials: %v", err)
+		}
 
-	// Title
-	markdown.WriteString(fmt.Sprintf("# %s\n\n", detailedCard.Name))
+		cfg.APIKey = apiKey
+		cfg.APIToken = apiToken
 
-	// Status
-	status := "Open"
-	if detailedCard.Closed {
-		status = "Closed"
+		// Save right away so validated credentials survive a failed board selection
+		if err := config.SaveConfig(cfg); err != nil {
+			log.Fatalf("Failed to save config: %v", err)
+		}
 	}
-	markdown.WriteString(fmt.Sprintf("**%s**\n\n", status))
 
-	// Description
-	if detailedCard.Desc != "" {
-		markdown.WriteString(fmt.Sprintf("## Description\n\n%s\n\n", detailedCard.Desc))
-	}
+	// Create Trello client
+	client := newClient(cfg)
 
-	// Assignees
-	if len(detailedCard.IDMembers) > 0 {
-		markdown.WriteString("## Assignees\n")
-		for _, memberID := range detailedCard.IDMembers {
-			// Look up member details to get full name
-			member, err := client.GetMember(memberID)
-			if err != nil {
-				// If lookup fails, show the ID
-				markdown.WriteString(fmt.Sprintf("- %s\n", memberID))
-			} else {
-				markdown.WriteString(fmt.Sprintf("- %s\n", member.FullName))
-			}
+	// If workspace or board is missing, prompt for selection
+	if cfg.Workspace == "" || cfg.BoardID == "" {
+		if err := selectBoard(client, cfg); err != nil {
+			log.Fatal(err)
 		}
-		markdown.WriteString("\n")
-	}
 
-	// Labels
-	if len(detailedCard.Labels) > 0 {
-		markdown.WriteString("## Labels\n")
-		for _, label := range detailedCard.Labels {
-			markdown.WriteString(fmt.Sprintf("- %s\n", label.Name))
+		// Save the config
+		if err := config.SaveConfig(cfg); err != nil {
+			log.Fatalf("Failed to save config: %v", err)
 		}
-		markdown.WriteString("\n")
 	}
 
-	// List
-	if listName, exists := listMap[detailedCard.IDList]; exists {
-		markdown.WriteString(fmt.Sprintf("## List\n\n%s\n\n", listName))
+	return client
+}
+
+func main() {
+	// Dispatch subcommands before parsing the listing flags
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "config":
+			runConfigCommand(os.Args[2:])
+			return
+		case "login":
+			runLoginCommand(os.Args[2:])
+			return
+		case "board":
+			runBoardCommand(os.Args[2:])
+			return
+		case "pick":
+			runPickCommand(os.Args[2:])
+			return
+		case "cache":
+			runCacheCommand(os.Args[2:])
+			return
+		case "sync":
+			runSyncCommand(os.Args[2:])
+			return
+		case "create":
+			runCreateCommand(os.Args[2:])
+			return
+		case "comment":
+			runCommentCommand(os.Args[2:])
+			return
+		case "move":
+			runMoveCommand(os.Args[2:])
+			return
+		case "queue":
+			runQueueCommand(os.Args[2:])
+			return
+		case "search":
+			runSearchCommand(os.Args[2:])
+			return
+		case "view":
+			runViewCommand(os.Args[2:])
+			return
+		case "branch":
+			runBranchCommand(os.Args[2:])
+			return
+		case "show":
+			runShowCommand(os.Args[2:])
+			return
+		case "hooks":
+			runHooksCommand(os.Args[2:])
+			return
+		case "pr-body":
+			runPRBodyCommand(os.Args[2:])
+			return
+		}
 	}
 
-	// Comments
-	if len(comments) > 0 {
-		markdown.WriteString(fmt.Sprintf("## Comments (%d)\n\n", len(comments)))
-		for i, comment := range comments {
-			commentTime, err := time.Parse(time.RFC3339, comment.Date)
-			timeStr := "Unknown time"
-			if err == nil {
-				timeStr = commentTime.Format("Jan 2, 2006 at 3:04 PM")
-			}
+	runListCommand(os.Args[1:])
+}
 
-			markdown.WriteString(fmt.Sprintf("### Comment %d\n\n", i+1))
-			markdown.WriteString(fmt.Sprintf("**%s** commented on %s:\n\n", comment.MemberCreator.FullName, timeStr))
-			markdown.WriteString(fmt.Sprintf("%s\n\n", comment.Data.Text))
-			markdown.WriteString("---\n\n")
-		}
+// listOptions holds the flags of the default listing command.
+type listOptions struct {
+	assignedOnly  bool
+	allCards      bool
+	listFilter    string
+	showCard      string
+	fieldFilter   string
+	workspace     bool
+	boardFilter   string
+	allWorkspaces bool
+	where         string
+	output        string
+	sort          string
+	columns       string
+	group         bool
+	collapseEmpty bool
+	offline       bool
+}
+
+// newListFlags defines the listing flags. Saved views are checked against
+// the same definitions.
+func newListFlags(errorHandling flag.ErrorHandling) (*flag.FlagSet, *listOptions) {
+	fs := flag.NewFlagSet("trello_cli", errorHandling)
+	opts := &listOptions{}
+
+	fs.BoolVar(&opts.assignedOnly, "assigned", true, "Show only cards assigned to current user")
+	fs.BoolVar(&opts.allCards, "all", false, "Show all cards on the board")
+	fs.StringVar(&opts.listFilter, "lists", "", "Filter cards by specific lists (comma-separated)")
+	fs.StringVar(&opts.showCard, "card", "", "Show detailed information for a card: #123, short link, card URL, card ID or title text")
+	fs.StringVar(&opts.fieldFilter, "field", "", "Show only specific field from card (with -c, or the card of the git branch): title, description, assignees, labels, list, status, created_at")
+	fs.BoolVar(&opts.assignedOnly, "a", true, "Show only cards assigned to current user (short)")
+	fs.BoolVar(&opts.allCards, "A", false, "Show all cards on the board (short)")
+	fs.StringVar(&opts.listFilter, "l", "", "Filter cards by specific lists (comma-separated, short)")
+	fs.StringVar(&opts.showCard, "c", "", "Show detailed information for a card: #123, short link, card URL, card ID or title text (short)")
+	fs.StringVar(&opts.fieldFilter, "f", "", "Show only specific field from card (with -c, or the card of the git branch): title, description, assignees, labels, list, status, created_at (short)")
+	fs.BoolVar(&opts.workspace, "workspace", false, "List cards from every open board in the configured workspace")
+	fs.StringVar(&opts.boardFilter, "boards", "", "List cards from these boards in the configured workspace (comma-separated names or IDs)")
+	fs.BoolVar(&opts.allWorkspaces, "all-workspaces", false, "List your cards from every board you belong to")
+	fs.StringVar(&opts.where, "where", "", "Only list cards matching an expression, e.g. 'list in (Doing, Review) and label = bug and due < 3d'")
+	fs.StringVar(&opts.where, "w", "", "Only list cards matching an expression (short)")
+	fs.StringVar(&opts.output, "output", "table", "Output format: "+outputFormats)
+	fs.StringVar(&opts.output, "o", "table", "Output format (short)")
+	fs.StringVar(&opts.sort, "sort", "board", "Sort by (comma-separated, add :desc to reverse): "+sortKeyNames)
+	fs.StringVar(&opts.columns, "columns", "", "Columns to show (comma-separated): "+columnNames)
+	fs.BoolVar(&opts.group, "group", false, "Group cards under their lists, in board order")
+	fs.BoolVar(&opts.group, "g", false, "Group cards under their lists (short)")
+	fs.BoolVar(&opts.collapseEmpty, "collapse-empty", false, "With --group, name lists without cards on one line instead of showing each")
+	fs.BoolVar(&opts.offline, "offline", false, "Read from the snapshot saved by 'trello_cli sync' instead of Trello")
+	addClientFlags(fs)
+
+	return fs, opts
+}
+
+// listSettings is the parsed form of the listing flags that take a value
+// language of their own.
+type listSettings struct {
+	where   *query.Query
+	sort    []sortKey
+	columns []listColumn
+}
+
+// validate checks flag combinations and parses --where, --sort and
+// --columns, so mistakes are reported before any requests are made.
+func (opts *listOptions) validate() (*listSettings, error) {
+	if err := checkOutputFormat(opts.output); err != nil {
+		return nil, err
 	}
 
-	// Card link
-	markdown.WriteString("## Links\n\n")
-	markdown.WriteString(fmt.Sprintf("- View this card on Trello: https://trello.com/c/%s\n", detailedCard.ShortLink))
-
-	// Handle field filtering - if fieldFilter is specified, output only that field
-	if fieldFilter != "" {
-		switch strings.ToLower(fieldFilter) {
-		case "title":
-			fmt.Print(detailedCard.Name)
-		case "description":
-			if detailedCard.Desc != "" {
-				fmt.Print(detailedCard.Desc)
-			}
-		case "status":
-			if detailedCard.Closed {
-				fmt.Print("Closed")
-			} else {
-				fmt.Print("Open")
-			}
-		case "assignees":
-			if len(detailedCard.IDMembers) > 0 {
-				var names []string
-				for _, memberID := range detailedCard.IDMembers {
-					member, err := client.GetMember(memberID)
-					if err != nil {
-						names = append(names, memberID)
-					} else {
-						names = append(names, member.FullName)
-					}
-				}
-				fmt.Print(strings.Join(names, ", "))
-			} else {
-				fmt.Print("No assignees")
-			}
-		case "labels":
-			if len(detailedCard.Labels) > 0 {
-				var labelNames []string
-				for _, label := range detailedCard.Labels {
-					labelNames = append(labelNames, label.Name)
-				}
-				fmt.Print(strings.Join(labelNames, ", "))
-			}
-		case "list":
-			if listName, exists := listMap[detailedCard.IDList]; exists {
-				fmt.Print(listName)
-			} else {
-				fmt.Print("Unknown")
-			}
-		case "link":
-			fmt.Printf("https://trello.com/c/%s", detailedCard.ShortLink)
-		case "created_at":
-			// Extract creation date from card ID (first 8 characters of ID are hexadecimal timestamp)
-			if len(detailedCard.ID) >= 8 {
-				// The first 8 characters of the card ID represent the timestamp in hexadecimal
-				timestampHex := detailedCard.ID[:8]
-				if timestamp, err := strconv.ParseInt(timestampHex, 16, 64); err == nil {
-					// Convert from seconds to milliseconds and create time
-					createdAt := time.Unix(timestamp, 0)
-					fmt.Print(createdAt.Format("2006-01-02 15:04:05"))
-				} else {
-					fmt.Print("Unknown")
-				}
-			} else {
-				fmt.Print("Unknown")
-			}
-		default:
-			log.Fatalf("Unknown field: %s. Available fields: title, description, status, assignees, labels, list, link, created_at", fieldFilter)
-		}
-		return
+	multiBoard := opts.multiBoard()
+	if multiBoard && opts.offline {
+		return nil, fmt.Errorf("--offline only covers the configured board; it cannot be combined with --workspace, --boards or --all-workspaces")
+	}
+	if opts.allWorkspaces && opts.allCards {
+		return nil, fmt.Errorf("--all-workspaces only lists your own cards; it cannot be combined with --all")
+	}
+	if opts.group && opts.output != "table" {
+		return nil, fmt.Errorf("--group only applies to table output")
+	}
+	if opts.collapseEmpty && !opts.group {
+		return nil, fmt.Errorf("--collapse-empty only applies with --group")
 	}
 
-	// Render markdown with glamour
-	var out string
+	settings := &listSettings{}
+	var err error
+	if settings.sort, err = parseSort(opts.sort); err != nil {
+		return nil, fmt.Errorf("invalid --sort: %w", err)
+	}
+	if settings.columns, err = parseColumns(opts.columns, opts.output, multiBoard); err != nil {
+		return nil, fmt.Errorf("invalid --columns: %w", err)
+	}
+	if opts.group && opts.columns == "" {
+		// The group headers already name the list and board
+		settings.columns = withoutColumns(settings.columns, "list", "board")
+	}
 
-	if os.Getenv("CLICOLOR_FORCE") == "1" {
-		// Force ANSI output even when piping
-		os.Setenv("NO_COLOR", "")
-		os.Setenv("COLORTERM", "256color")
-		os.Setenv("TERM", "xterm-256color")
-		// Use Render function with dark style
-		var err error
-		out, err = glamour.Render(markdown.String(), "dark")
-		if err != nil {
-			log.Fatalf("Failed to render markdown: %v", err)
-		}
-	} else {
-		// Use auto-style for adaptive coloring
-		r, _ := glamour.NewTermRenderer(
-			glamour.WithAutoStyle(),
-			glamour.WithWordWrap(0),
-		)
-		var err error
-		out, err = r.Render(markdown.String())
-		if err != nil {
-			log.Fatalf("Failed to render markdown: %v", err)
+	if strings.TrimSpace(opts.where) != "" {
+		if settings.where, err = query.Parse(opts.where); err != nil {
+			return nil, fmt.Errorf("invalid --where expression: %w", err)
 		}
 	}
+	return settings, nil
+}
 
-	fmt.Print(out)
+// multiBoard reports whether the listing spans more than the configured board.
+func (opts *listOptions) multiBoard() bool {
+	return opts.workspace || opts.boardFilter != "" || opts.allWorkspaces
 }
 
-func main() {
-	// Define CLI flags
-	assignedOnly := flag.Bool("assigned", true, "Show only cards assigned to current user")
-	allCards := flag.Bool("all", false, "Show all cards on the board")
-	listFilter := flag.String("lists", "", "Filter cards by specific lists (comma-separated)")
-	showCard := flag.String("card", "", "Show detailed information for a specific card by ID (format: #123 or 123)")
-	fieldFilter := flag.String("field", "", "Show only specific field from card (use with -c): title, description, assignees, labels, list, status, created_at")
-	flag.BoolVar(assignedOnly, "a", true, "Show only cards assigned to current user (short)")
-	flag.BoolVar(allCards, "A", false, "Show all cards on the board (short)")
-	flag.StringVar(listFilter, "l", "", "Filter cards by specific lists (comma-separated, short)")
-	flag.StringVar(showCard, "c", "", "Show detailed information for a specific card by ID (format: #123 or 123, short)")
-	flag.StringVar(fieldFilter, "f", "", "Show only specific field from card (use with -c): title, description, assignees, labels, list, status, created_at (short)")
-	flag.Parse()
-
-	// Validate flags - if both are set, prefer --all
-	if *assignedOnly && *allCards {
-		fmt.Println("Warning: Both --assigned and --all flags specified. Using --all.")
-	}
-
-	// Handle card detail view
-	if *showCard != "" {
-		// Parse card ID from format #123 or 123 (bare integer)
-		idStr := *showCard
-
-		// Remove # prefix if present
-		if strings.HasPrefix(idStr, "#") {
-			idStr = strings.TrimPrefix(idStr, "#")
-		}
+// runListCommand lists cards: the command run when no subcommand is given.
+func runListCommand(args []string) {
+	fs, opts := newListFlags(flag.ExitOnError)
+	fs.Parse(args)
 
-		// Check if we have a valid string after removing prefix
-		if idStr == "" {
-			log.Fatalf("Invalid card ID format. Use format: #123 or 123")
-		}
+	// Validate flags - if both are set, prefer --all. Warn on stderr so json
+	// and tsv output stay parseable.
+	if opts.assignedOnly && opts.allCards {
+		fmt.Fprintln(os.Stderr, "Warning: Both --assigned and --all flags specified. Using --all.")
+	}
 
-		// Parse to integer
-		cardID, err := strconv.Atoi(idStr)
-		if err != nil {
-			log.Fatalf("Invalid card ID: %s (must be numeric)", idStr)
+	// Handle card detail view; -f alone uses the card of the git branch
+	if opts.showCard != "" || opts.fieldFilter != "" {
+		if opts.showCard != "" && strings.TrimSpace(strings.TrimPrefix(opts.showCard, "#")) == "" {
+			log.Fatalf("Invalid card reference. Use #123, a short link, a card URL, a card ID or part of the title")
 		}
 
-		showCardDetails(cardID, *fieldFilter)
+		showCardDetails(opts.showCard, opts.fieldFilter, opts.offline)
 		return
 	}
 
 	// Parse list filter
 	var allowedLists map[string]bool
-	if *listFilter != "" {
+	if opts.listFilter != "" {
 		allowedLists = make(map[string]bool)
-		listNames := strings.Split(*listFilter, ",")
-		for _, name := range listNames {
-			// Trim whitespace and convert to lowercase for case-insensitive matching
-			trimmedName := strings.TrimSpace(name)
-			if trimmedName != "" {
-				allowedLists[strings.ToLower(trimmedName)] = true
-			}
+		for _, name := range splitList(opts.listFilter) {
+			// Convert to lowercase for case-insensitive matching
+			allowedLists[strings.ToLower(name)] = true
 		}
 	}
 
@@ -303,167 +324,88 @@ func main() {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
-	// If API credentials are missing, prompt for them
-	if cfg.APIKey == "" || cfg.APIToken == "" {
-		fmt.Println("Please provide your Trello API credentials:")
-		apiKey, apiToken, _, _, err := PromptForConfig()
-		if err != nil {
-			log.Fatalf("Failed to get API credentials: %v", err)
-		}
-
-		cfg.APIKey = apiKey
-		cfg.APIToken = apiToken
-
-		// Test the credentials by creating a client and fetching user info
-		testClient := trello.NewClient(cfg.APIKey, cfg.APIToken)
-		_, err = testClient.GetMemberID()
-		if err != nil {
-			log.Fatalf("Invalid API credentials: %v", err)
-		}
+	settings, err := opts.validate()
+	if err != nil {
+		log.Fatal(err)
 	}
 
-	// Create Trello client
-	client := trello.NewClient(cfg.APIKey, cfg.APIToken)
+	var (
+		reader boardReader
+		client *trello.Client
+	)
+	if opts.offline {
+		reader, client = mustLoadOffline(cfg)
+	} else {
+		client = mustSetUpClient(cfg)
+		reader = client
+	}
 
-	// If workspace or board is missing, prompt for selection
-	if cfg.Workspace == "" || cfg.BoardID == "" {
-		fmt.Println("Fetching available workspaces...")
+	// Get current user ID
+	userID, err := reader.GetMemberID()
+	if err != nil {
+		log.Fatalf("Failed to get user ID: %v", err)
+	}
 
-		workspaceID, err := PromptForOrganization(client)
+	var (
+		rows  []cardRow
+		cards []trello.Card
+		lists []trello.List
+	)
+	switch {
+	case opts.allWorkspaces:
+		rows, err = memberRows(client, userID, allowedLists)
 		if err != nil {
-			log.Fatalf("Failed to select workspace: %v", err)
+			log.Fatal(err)
 		}
-
-		fmt.Println("Fetching available boards...")
-
-		boardID, err := PromptForBoard(client, workspaceID)
+	case opts.workspace || opts.boardFilter != "":
+		boards, err := workspaceBoards(client, cfg, splitList(opts.boardFilter))
 		if err != nil {
-			log.Fatalf("Failed to select board: %v", err)
+			log.Fatal(err)
 		}
-
-		cfg.Workspace = workspaceID
-		cfg.BoardID = boardID
-
-		// Save the config
-		if err := config.SaveConfig(cfg); err != nil {
-			log.Fatalf("Failed to save config: %v", err)
+		rows = boardRows(client, boards, userID, opts.allCards, allowedLists)
+	default:
+		// Get cards from the board
+		cards, err = reader.GetCards(cfg.BoardID)
+		if err != nil {
+			log.Fatalf("Failed to get cards: %v", err)
 		}
-	}
 
-	// Get current user ID
-	userID, err := client.GetMemberID()
-	if err != nil {
-		log.Fatalf("Failed to get user ID: %v", err)
-	}
-
-	// Get cards from the board
-	cards, err := client.GetCards(cfg.BoardID)
-	if err != nil {
-		log.Fatalf("Failed to get cards: %v", err)
-	}
+		// Get lists from the board for lookup
+		lists, err = reader.GetLists(cfg.BoardID)
+		if err != nil {
+			log.Fatalf("Failed to get lists: %v", err)
+		}
 
-	// Get lists from the board for lookup
-	lists, err := client.GetLists(cfg.BoardID)
-	if err != nil {
-		log.Fatalf("Failed to get lists: %v", err)
+		rows = collectRows(cards, lists, "", userID, opts.allCards, allowedLists)
 	}
 
-	// Create a map of list ID to list name for quick lookup
-	listMap := make(map[string]string)
-	for _, list := range lists {
-		listMap[list.ID] = list.Name
+	// Member names are only looked up when something needs them
+	if usesColumn(settings.columns, "members") || (settings.where != nil && settings.where.Uses("member")) {
+		resolveRowMembers(rows, client)
 	}
-
-	// Helper function to check if a list should be included
-	shouldIncludeList := func(listName string) bool {
-		if allowedLists == nil {
-			return true // No filter specified, include all
-		}
-		return allowedLists[strings.ToLower(listName)]
-	}
-
-	// Collect cards to display based on filtering
-	var cardsToDisplay []struct {
-		id       int
-		name     string
-		listName string
-	}
-
-	if *allCards {
-		// Show all cards
-		for _, card := range cards {
-			listName := listMap[card.IDList]
-			if listName == "" {
-				listName = "Unknown"
-			}
-			if shouldIncludeList(listName) {
-				cardsToDisplay = append(cardsToDisplay, struct {
-					id       int
-					name     string
-					listName string
-				}{card.IDShort, card.Name, listName})
-			}
-		}
-	} else {
-		// Show only cards assigned to current user (default behavior)
-		for _, card := range cards {
-			for _, memberID := range card.IDMembers {
-				if memberID == userID {
-					listName := listMap[card.IDList]
-					if listName == "" {
-						listName = "Unknown"
-					}
-					if shouldIncludeList(listName) {
-						cardsToDisplay = append(cardsToDisplay, struct {
-							id       int
-							name     string
-							listName string
-						}{card.IDShort, card.Name, listName})
-					}
-					break
-				}
-			}
-		}
+	if settings.where != nil {
+		rows = filterRows(rows, settings.where, userID)
 	}
 
-	// Sort cards by list name first, then by ShortID (ascending)
-	sort.Slice(cardsToDisplay, func(i, j int) bool {
-		if cardsToDisplay[i].listName != cardsToDisplay[j].listName {
-			return cardsToDisplay[i].listName < cardsToDisplay[j].listName
-		}
-		return cardsToDisplay[i].id < cardsToDisplay[j].id
-	})
-
-	// Calculate list column width
-	maxListWidth := 0
-	for _, card := range cardsToDisplay {
-		if len(card.listName) > maxListWidth {
-			maxListWidth = len(card.listName)
-		}
+	sortRows(rows, settings.sort)
+	if opts.group {
+		// Only the configured board's lists are known, so other modes group
+		// the rows alone and skip empty lists
+		printGroups(cfg, groupRows(rows, lists, cards, allowedLists), settings.columns, opts.collapseEmpty)
+		return
 	}
-
-	// Ensure minimum widths for better formatting
-	if maxListWidth < 10 { // Minimum list name width
-		maxListWidth = 10
+	if err := writeRows(rows, opts.output, settings.columns); err != nil {
+		log.Fatal(err)
 	}
+}
 
-	// Print cards with fixed column widths
-	for _, card := range cardsToDisplay {
-		idStr := fmt.Sprintf("#%d", card.id)
-		// Truncate title to 80 characters if needed
-		title := card.name
-		if len(title) > 80 {
-			title = title[:77] + "..."
+// splitList splits a comma-separated flag value, dropping empty entries.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
 		}
-
-		// Format: ID (fixed width) + Title (fixed width) + List (fixed width)
-		styledID := lipgloss.NewStyle().Foreground(lipgloss.Color("3"))   // Yellow color
-		styledList := lipgloss.NewStyle().Foreground(lipgloss.Color("8")) // Gray color
-
-		// Apply styles after width formatting to maintain proper alignment
-		idFormatted := fmt.Sprintf("%-8s", idStr)
-		titleFormatted := fmt.Sprintf("%-80s", title)
-
-		fmt.Printf("%s %s %s\n", styledID.Render(idFormatted), titleFormatted, styledList.Render(card.listName))
 	}
+	return items
 }
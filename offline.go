@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"time"
+	"trello_cli/config"
+	"trello_cli/store"
+	"trello_cli/trello"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var offlineNoticeStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("3"))
+
+// mustLoadOffline loads the snapshot of the configured board for --offline.
+// The returned client never touches the network; it only serves the
+// snapshot's members to the card renderers.
+func mustLoadOffline(cfg *config.Config) (*store.Snapshot, *trello.Client) {
+	if cfg.BoardID == "" {
+		log.Fatalf("No board selected. Run 'trello_cli config switch-board' to choose one.")
+	}
+
+	snap, err := store.LoadSnapshot(cfg.BoardID)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	client := trello.NewClient(cfg.APIKey, cfg.APIToken)
+	client.SetOffline()
+	client.PrimeMembers(snap.Members)
+
+	// Report the age on stderr so output piped from -f stays clean
+	notice := fmt.Sprintf("Offline: %s as of %s (%s)",
+		snap.Board.Name, snap.SyncedAt.Local().Format("Jan 2 15:04"), formatAge(snap.Age()))
+	if ops, err := store.LoadQueue(); err == nil && len(ops) > 0 {
+		notice += fmt.Sprintf(", %d change(s) queued", len(ops))
+	}
+	fmt.Fprintln(os.Stderr, offlineNoticeStyle.Render(notice))
+
+	return snap, client
+}
+
+// formatAge describes a duration the way people say it: "5 minutes ago".
+func formatAge(d time.Duration) string {
+	plural := func(n int, unit string) string {
+		if n == 1 {
+			return fmt.Sprintf("1 %s ago", unit)
+		}
+		return fmt.Sprintf("%d %ss ago", n, unit)
+	}
+
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return plural(int(d/time.Minute), "minute")
+	case d < 24*time.Hour:
+		return plural(int(d/time.Hour), "hour")
+	}
+	return plural(int(d/(24*time.Hour)), "day")
+}
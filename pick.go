@@ -0,0 +1,159 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"trello_cli/config"
+	"trello_cli/trello"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const pickFormats = "id, title, link, shortlink, branch, description, list, labels"
+
+func runPickCommand(args []string) {
+	fs := flag.NewFlagSet("pick", flag.ExitOnError)
+	mine := fs.Bool("mine", false, "Only offer cards assigned to you")
+	format := fs.String("format", "id", "Field to print for the chosen card: "+pickFormats)
+	fs.StringVar(format, "f", "id", "Field to print for the chosen card (short)")
+	addClientFlags(fs)
+	fs.Parse(args)
+
+	cfg, client := mustLoadClient()
+
+	cards, err := client.GetCards(cfg.BoardID)
+	if err != nil {
+		log.Fatalf("Failed to get cards: %v", err)
+	}
+
+	lists, err := client.GetLists(cfg.BoardID)
+	if err != nil {
+		log.Fatalf("Failed to get lists: %v", err)
+	}
+	listMap := make(map[string]string)
+	for _, list := range lists {
+		listMap[list.ID] = list.Name
+	}
+
+	if *mine {
+		userID, err := client.GetMemberID()
+		if err != nil {
+			log.Fatalf("Failed to get user ID: %v", err)
+		}
+		var assigned []trello.Card
+		for _, card := range cards {
+			if containsString(card.IDMembers, userID) {
+				assigned = append(assigned, card)
+			}
+		}
+		cards = assigned
+	}
+
+	if len(cards) == 0 {
+		log.Fatalf("No cards to pick from")
+	}
+
+	// Validate the format before asking the user to choose anything
+	if _, err := pickField(cfg, cards[0], listMap, *format); err != nil {
+		log.Fatal(err)
+	}
+
+	card, err := pickCard("Pick a card", cards, listMap)
+	if errors.Is(err, errPickerCancelled) {
+		os.Exit(1)
+	}
+	if err != nil {
+		log.Fatalf("Failed to pick card: %v", err)
+	}
+
+	value, _ := pickField(cfg, *card, listMap, *format)
+	fmt.Println(value)
+}
+
+// pickCard lets the user fuzzy-search cards by number, title, labels and list
+// with a preview of the description. The picker draws on the terminal
+// directly so stdout can be captured, as in $(trello_cli pick).
+func pickCard(title string, cards []trello.Card, listMap map[string]string) (*trello.Card, error) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return nil, fmt.Errorf("an interactive terminal is required: %w", err)
+	}
+	defer tty.Close()
+
+	items := make([]pickerItem, len(cards))
+	for i, card := range cards {
+		items[i] = pickerItem{
+			title:  fmt.Sprintf("#%d %s", card.IDShort, card.Name),
+			detail: strings.TrimSpace(listMap[card.IDList] + " " + strings.Join(cardLabelNames(card), " ")),
+		}
+	}
+
+	// Styles must target the terminal, not the possibly redirected stdout
+	picker := newPicker(title, items)
+	picker.setRenderer(lipgloss.NewRenderer(tty))
+	styles := picker.styles
+	picker.preview = func(i int) string {
+		return cardPreview(cards[i], listMap, styles)
+	}
+
+	choice, err := runPickerModel(picker, tea.WithInput(tty), tea.WithOutput(tty))
+	if err != nil {
+		return nil, err
+	}
+	return &cards[choice], nil
+}
+
+func cardPreview(card trello.Card, listMap map[string]string, styles pickerStyles) string {
+	var b strings.Builder
+	b.WriteString(styles.title.Render(card.Name) + "\n")
+
+	meta := []string{listMap[card.IDList]}
+	if labels := cardLabelNames(card); len(labels) > 0 {
+		meta = append(meta, strings.Join(labels, ", "))
+	}
+	b.WriteString(styles.detail.Render(strings.Join(meta, " • ")) + "\n\n")
+
+	if card.Desc == "" {
+		b.WriteString(styles.detail.Render("No description"))
+	} else {
+		b.WriteString(card.Desc)
+	}
+	return b.String()
+}
+
+func cardLabelNames(card trello.Card) []string {
+	var names []string
+	for _, label := range card.Labels {
+		names = append(names, labelDisplayName(label))
+	}
+	return names
+}
+
+// pickField formats the chosen card for stdout.
+func pickField(cfg *config.Config, card trello.Card, listMap map[string]string, format string) (string, error) {
+	switch strings.ToLower(format) {
+	case "id":
+		return strconv.Itoa(card.IDShort), nil
+	case "title":
+		return card.Name, nil
+	case "link":
+		return cardURL(card), nil
+	case "shortlink":
+		return card.ShortLink, nil
+	case "branch":
+		return cardBranchName(cfg, card)
+	case "description":
+		return card.Desc, nil
+	case "list":
+		return listMap[card.IDList], nil
+	case "labels":
+		return strings.Join(cardLabelNames(card), ", "), nil
+	}
+	return "", fmt.Errorf("unknown format: %s. Available formats: %s", format, pickFormats)
+}
@@ -0,0 +1,320 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var errPickerCancelled = errors.New("selection cancelled")
+
+// pickerStyles are the picker's styles bound to one renderer, so a picker
+// drawn on /dev/tty can use the terminal's colors without changing the
+// process-wide default that stdout output relies on.
+type pickerStyles struct {
+	title    lipgloss.Style
+	cursor   lipgloss.Style
+	match    lipgloss.Style
+	detail   lipgloss.Style
+	help     lipgloss.Style
+	selected lipgloss.Style
+	plain    lipgloss.Style
+}
+
+func newPickerStyles(r *lipgloss.Renderer) pickerStyles {
+	return pickerStyles{
+		title:    r.NewStyle().Bold(true),
+		cursor:   r.NewStyle().Foreground(lipgloss.Color("205")),
+		match:    r.NewStyle().Foreground(lipgloss.Color("205")).Underline(true),
+		detail:   r.NewStyle().Foreground(lipgloss.Color("240")),
+		help:     r.NewStyle().Foreground(lipgloss.Color("240")),
+		selected: r.NewStyle().Bold(true),
+		plain:    r.NewStyle(),
+	}
+}
+
+type pickerItem struct {
+	title  string // shown and matched
+	detail string // shown dimmed after the title and also matched
+}
+
+// pickerModel is a type-to-filter list selector. It is used standalone via
+// runPicker and embedded in other models, so it reports completion through
+// done/chosen instead of quitting the program itself.
+type pickerModel struct {
+	title   string
+	items   []pickerItem
+	filter  textinput.Model
+	matches []fuzzyMatch
+	cursor  int
+	offset  int
+	height  int
+	width   int
+	styles  pickerStyles
+
+	// preview, when set, renders details for the highlighted item below
+	// the list
+	preview func(index int) string
+
+	done   bool
+	chosen int // index into items, -1 when cancelled
+}
+
+func newPicker(title string, items []pickerItem) pickerModel {
+	filter := textinput.New()
+	filter.Prompt = "> "
+	filter.Placeholder = "type to filter"
+	filter.Width = 40
+	filter.Focus()
+
+	m := pickerModel{
+		title:  title,
+		items:  items,
+		filter: filter,
+		height: 10,
+		chosen: -1,
+	}
+	m.setRenderer(lipgloss.DefaultRenderer())
+	m.refilter()
+	return m
+}
+
+// setRenderer restyles the picker for output through r.
+func (m *pickerModel) setRenderer(r *lipgloss.Renderer) {
+	m.styles = newPickerStyles(r)
+	m.filter.PromptStyle = m.styles.cursor
+	m.filter.TextStyle = m.styles.plain
+	m.filter.PlaceholderStyle = m.styles.detail
+	m.filter.Cursor.Style = m.styles.plain
+	m.filter.Cursor.TextStyle = m.styles.plain
+}
+
+func (m *pickerModel) refilter() {
+	candidates := make([]string, len(m.items))
+	for i, item := range m.items {
+		candidates[i] = item.title + " " + item.detail
+	}
+	m.matches = fuzzyFilter(m.filter.Value(), candidates)
+	m.cursor = 0
+	m.offset = 0
+}
+
+// selected returns the item index under the cursor, or -1 when nothing matches.
+func (m pickerModel) selected() int {
+	if m.cursor < len(m.matches) {
+		return m.matches[m.cursor].index
+	}
+	return -1
+}
+
+func (m *pickerModel) move(delta int) {
+	m.cursor += delta
+	if m.cursor >= len(m.matches) {
+		m.cursor = len(m.matches) - 1
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+
+	// Keep the cursor inside the visible window
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+m.height {
+		m.offset = m.cursor - m.height + 1
+	}
+}
+
+func (m pickerModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m pickerModel) Update(msg tea.Msg) (pickerModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		// Leave room for the title, filter, counter and help lines
+		m.height = msg.Height - 5
+		m.width = msg.Width
+		if m.preview != nil {
+			// Split the space between the list and the preview pane
+			m.height = m.height / 2
+		}
+		if m.height < 3 {
+			m.height = 3
+		}
+		m.move(0)
+		return m, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "esc":
+			m.done = true
+			m.chosen = -1
+			return m, nil
+		case "enter":
+			if i := m.selected(); i >= 0 {
+				m.done = true
+				m.chosen = i
+			}
+			return m, nil
+		case "up", "ctrl+p", "ctrl+k":
+			m.move(-1)
+			return m, nil
+		case "down", "ctrl+n", "ctrl+j":
+			m.move(1)
+			return m, nil
+		case "pgup":
+			m.move(-m.height)
+			return m, nil
+		case "pgdown":
+			m.move(m.height)
+			return m, nil
+		}
+	}
+
+	before := m.filter.Value()
+	var cmd tea.Cmd
+	m.filter, cmd = m.filter.Update(msg)
+	if m.filter.Value() != before {
+		m.refilter()
+	}
+	return m, cmd
+}
+
+func (m pickerModel) View() string {
+	var b strings.Builder
+
+	if m.title != "" {
+		b.WriteString(m.styles.title.Render(m.title) + "\n")
+	}
+	b.WriteString(m.filter.View() + "\n")
+
+	end := m.offset + m.height
+	if end > len(m.matches) {
+		end = len(m.matches)
+	}
+	for i := m.offset; i < end; i++ {
+		match := m.matches[i]
+		item := m.items[match.index]
+
+		cursor := "  "
+		if i == m.cursor {
+			cursor = m.styles.cursor.Render("▸ ")
+		}
+
+		line := m.highlightMatches(item.title, match.positions, i == m.cursor)
+		if item.detail != "" {
+			line += "  " + m.styles.detail.Render(item.detail)
+		}
+		b.WriteString(cursor + line + "\n")
+	}
+
+	b.WriteString(m.styles.help.Render(fmt.Sprintf("%d/%d • ↑/↓ move • enter select • esc cancel", len(m.matches), len(m.items))))
+
+	if m.preview != nil {
+		if i := m.selected(); i >= 0 {
+			b.WriteString("\n" + m.renderPreview(i))
+		}
+	}
+	return b.String()
+}
+
+// renderPreview draws the preview for item i, clipped to the space left
+// below the list.
+func (m pickerModel) renderPreview(i int) string {
+	width := m.width
+	if width <= 0 {
+		width = 80
+	}
+
+	rule := m.styles.detail.Render(strings.Repeat("─", width))
+	body := m.styles.plain.Width(width).Render(m.preview(i))
+
+	lines := strings.Split(body, "\n")
+	if len(lines) > m.height {
+		lines = lines[:m.height]
+	}
+	return rule + "\n" + strings.Join(lines, "\n")
+}
+
+// highlightMatches styles the matched rune positions of title. Positions past
+// the title belong to the detail text and are ignored.
+func (m pickerModel) highlightMatches(title string, positions []int, selected bool) string {
+	base := m.styles.plain
+	if selected {
+		base = m.styles.selected
+	}
+
+	matched := make(map[int]bool, len(positions))
+	for _, p := range positions {
+		matched[p] = true
+	}
+
+	var b strings.Builder
+	for i, r := range []rune(title) {
+		if matched[i] {
+			b.WriteString(m.styles.match.Render(string(r)))
+		} else {
+			b.WriteString(base.Render(string(r)))
+		}
+	}
+	return b.String()
+}
+
+// pickerApp runs a pickerModel as a standalone program.
+type pickerApp struct {
+	picker pickerModel
+}
+
+func (a pickerApp) Init() tea.Cmd {
+	return a.picker.Init()
+}
+
+func (a pickerApp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	a.picker, cmd = a.picker.Update(msg)
+	if a.picker.done {
+		return a, tea.Quit
+	}
+	return a, cmd
+}
+
+func (a pickerApp) View() string {
+	if a.picker.done {
+		return ""
+	}
+	return a.picker.View() + "\n"
+}
+
+// runPicker shows a filterable list and returns the index of the chosen item.
+// It returns errPickerCancelled when the user backs out.
+func runPicker(title string, items []pickerItem, opts ...tea.ProgramOption) (int, error) {
+	return runPickerModel(newPicker(title, items), opts...)
+}
+
+// runPickerModel is runPicker for a pre-configured picker, e.g. one with a
+// preview pane.
+func runPickerModel(picker pickerModel, opts ...tea.ProgramOption) (int, error) {
+	if len(picker.items) == 0 {
+		return -1, fmt.Errorf("nothing to choose from")
+	}
+
+	p := tea.NewProgram(pickerApp{picker: picker}, opts...)
+	m, err := p.Run()
+	if err != nil {
+		return -1, err
+	}
+
+	app, ok := m.(pickerApp)
+	if !ok {
+		return -1, fmt.Errorf("unexpected model type")
+	}
+	if app.picker.chosen < 0 {
+		return -1, errPickerCancelled
+	}
+	return app.picker.chosen, nil
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"trello_cli/trello"
+)
+
+// defaultPRTemplate is used unless pr_template or --template names a file.
+const defaultPRTemplate = `## {{.Title}} (#{{.Number}})
+
+{{with .Description}}{{.}}
+
+{{end}}{{.ChecklistMarkdown}}{{with .Labels}}**Labels:** {{join . ", "}}
+
+{{end}}Trello: {{.Link}}
+`
+
+// prBodyData is what pull request templates can refer to.
+type prBodyData struct {
+	Number      int
+	Title       string
+	Description string
+	List        string
+	Labels      []string
+	Link        string
+	Checklists  []prChecklist
+
+	// ChecklistMarkdown is every checklist as a task list under a ### heading
+	ChecklistMarkdown string
+}
+
+type prChecklist struct {
+	Name  string
+	Items []prCheckItem
+}
+
+type prCheckItem struct {
+	Name string
+	Done bool
+}
+
+var prTemplateFuncs = template.FuncMap{
+	"join": strings.Join,
+}
+
+func runPRBodyCommand(args []string) {
+	fs := flag.NewFlagSet("pr-body", flag.ExitOnError)
+	templatePath := fs.String("template", "", "Template file to use instead of pr_template or the built-in one")
+	offline := fs.Bool("offline", false, "Read from the snapshot saved by 'trello_cli sync' instead of Trello")
+	addClientFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: trello_cli pr-body [flags] [card]\n\n")
+		fmt.Fprintf(fs.Output(), "Prints a pull request description for a card, by default the one named by the\ncurrent git branch.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	cfg, _, reader := mustLoadReader(*offline)
+
+	// Check the template before looking anything up
+	path := *templatePath
+	if path == "" {
+		path = cfg.PRTemplate
+	}
+	tmpl, err := loadPRTemplate(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	card := mustFindCard(cfg, reader, strings.TrimSpace(strings.Join(fs.Args(), " ")))
+	// Render fully first so a template error doesn't leave half a description
+	var body strings.Builder
+	if err := tmpl.Execute(&body, newPRBodyData(card)); err != nil {
+		log.Fatalf("Failed to render pull request template: %v", err)
+	}
+	fmt.Print(body.String())
+}
+
+// loadPRTemplate parses the template file at path, or the built-in template
+// when path is empty. A leading ~/ refers to the home directory.
+func loadPRTemplate(path string) (*template.Template, error) {
+	tmpl := template.New("pr-body").Funcs(prTemplateFuncs).Option("missingkey=error")
+	if path == "" {
+		return tmpl.Parse(defaultPRTemplate)
+	}
+
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		path = filepath.Join(home, rest)
+	}
+	text, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pull request template: %w", err)
+	}
+	if tmpl, err = tmpl.Parse(string(text)); err != nil {
+		return nil, fmt.Errorf("invalid pull request template %s: %w", path, err)
+	}
+	return tmpl, nil
+}
+
+func newPRBodyData(card *trello.DetailedCard) prBodyData {
+	data := prBodyData{
+		Number:            card.IDShort,
+		Title:             card.Name,
+		Description:       strings.TrimSpace(card.Desc),
+		Link:              shortCardURL(card.ShortLink),
+		ChecklistMarkdown: checklistMarkdown(card.Checklists, "###"),
+	}
+	if card.List != nil {
+		data.List = card.List.Name
+	}
+	for _, label := range card.Labels {
+		if label.Name != "" {
+			data.Labels = append(data.Labels, label.Name)
+		}
+	}
+	for _, checklist := range card.Checklists {
+		c := prChecklist{Name: checklist.Name}
+		for _, item := range checklist.CheckItems {
+			c.Items = append(c.Items, prCheckItem{Name: item.Name, Done: item.State == "complete"})
+		}
+		data.Checklists = append(data.Checklists, c)
+	}
+	return data
+}
@@ -1,8 +1,14 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
+	"strings"
+	"time"
 
+	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -14,35 +20,131 @@ var (
 	noStyle       = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
 	inputStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
 	continueStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	errorStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+	successStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
 )
 
+var errSetupCancelled = errors.New("setup cancelled")
+
 type model struct {
-	inputs  []textinput.Model
-	focused int
-	err     error
+	inputs      []textinput.Model
+	focused     int
+	spinner     spinner.Model
+	validating  bool
+	authorizing bool
+	member      *trello.Member
+	notice      string
+	err         error
+	cancelled   bool
 }
 
 type (
-	errMsg error
+	// errMsg reports a failed credential check
+	errMsg struct {
+		err error
+	}
+
+	// authorizedMsg carries a token obtained through the browser flow
+	authorizedMsg struct {
+		token string
+	}
+
+	// validatedMsg carries the account the entered credentials belong to
+	validatedMsg struct {
+		member *trello.Member
+	}
 )
 
-func initialModel() model {
+func initialModel(apiKey, apiToken string) model {
 	var inputs []textinput.Model = make([]textinput.Model, 2)
 
 	inputs[0] = textinput.New()
 	inputs[0].Placeholder = "Enter your Trello API Key"
-	inputs[0].Focus()
 	inputs[0].Width = 50
+	inputs[0].SetValue(apiKey)
 
 	inputs[1] = textinput.New()
 	inputs[1].Placeholder = "Enter your Trello API Token"
 	inputs[1].Width = 50
+	inputs[1].EchoMode = textinput.EchoPassword
+	inputs[1].EchoCharacter = '•'
+	inputs[1].SetValue(apiToken)
+
+	s := spinner.New()
+	s.Spinner = spinner.Dot
+	s.Style = focusedStyle
 
-	return model{
+	m := model{
 		inputs:  inputs,
-		focused: 0,
+		spinner: s,
 		err:     nil,
 	}
+
+	// Start on the token when the key is already known
+	if apiKey != "" {
+		m.focused = 1
+	}
+	m.focusInputs()
+
+	return m
+}
+
+func (m *model) focusInputs() tea.Cmd {
+	var cmds []tea.Cmd
+	for i := 0; i <= len(m.inputs)-1; i++ {
+		if i == m.focused {
+			cmds = append(cmds, m.inputs[i].Focus())
+			m.inputs[i].PromptStyle = focusedStyle
+			m.inputs[i].TextStyle = focusedStyle
+			continue
+		}
+		m.inputs[i].Blur()
+		m.inputs[i].PromptStyle = noStyle
+		m.inputs[i].TextStyle = noStyle
+	}
+	return tea.Batch(cmds...)
+}
+
+// authorizeInBrowser runs the loopback authorization flow for apiKey and
+// reports the resulting token.
+func authorizeInBrowser(apiKey string) tea.Cmd {
+	return func() tea.Msg {
+		session, err := trello.StartAuthorization(trello.AuthRequest{
+			APIKey:       apiKey,
+			AppName:      "trello_cli",
+			Scope:        "read,write",
+			Expiration:   "never",
+			AuthorizeURL: os.Getenv("TRELLO_AUTHORIZE_URL"),
+		})
+		if err != nil {
+			return errMsg{err: err}
+		}
+		if err := openBrowser(session.URL); err != nil {
+			session.Close()
+			return errMsg{err: fmt.Errorf("could not open a browser; run 'trello_cli login --no-browser' instead")}
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
+
+		token, err := session.Wait(ctx)
+		if err != nil {
+			return errMsg{err: err}
+		}
+		return authorizedMsg{token: token}
+	}
+}
+
+// validateCredentials checks the credentials against /members/me off the UI
+// goroutine so the spinner keeps animating.
+func validateCredentials(apiKey, apiToken string) tea.Cmd {
+	return func() tea.Msg {
+		member, err := trello.NewClient(apiKey, apiToken).GetCurrentMember()
+		if err != nil {
+			return errMsg{err: err}
+		}
+		return validatedMsg{member: member}
+	}
 }
 
 func (m model) Init() tea.Cmd {
@@ -53,16 +155,91 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmds []tea.Cmd = make([]tea.Cmd, len(m.inputs))
 
 	switch msg := msg.(type) {
+	case validatedMsg:
+		m.validating = false
+		m.member = msg.member
+		return m, tea.Quit
+
+	case authorizedMsg:
+		m.authorizing = false
+		m.notice = ""
+		m.inputs[1].SetValue(msg.token)
+		m.validating = true
+		return m, tea.Batch(m.spinner.Tick, validateCredentials(strings.TrimSpace(m.inputs[0].Value()), msg.token))
+
+	case errMsg:
+		m.validating = false
+		m.authorizing = false
+		m.notice = ""
+		m.err = msg.err
+		// Send the user back to the token, the usual culprit
+		m.focused = 1
+		return m, m.focusInputs()
+
+	case spinner.TickMsg:
+		if !m.validating {
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+c", "esc":
+		if msg.String() == "ctrl+c" || msg.String() == "esc" {
+			m.cancelled = true
 			return m, tea.Quit
+		}
+
+		// Ignore edits while a check is in flight
+		if m.validating {
+			return m, nil
+		}
+
+		switch msg.String() {
+		case "ctrl+a":
+			if m.authorizing {
+				return m, nil
+			}
+			if strings.TrimSpace(m.inputs[0].Value()) == "" {
+				m.err = fmt.Errorf("enter the API key first, then press ctrl+a")
+				return m, nil
+			}
+			m.err = nil
+			m.authorizing = true
+			m.notice = "Waiting for you to allow access in the browser..."
+			return m, authorizeInBrowser(strings.TrimSpace(m.inputs[0].Value()))
+
+		case "ctrl+o":
+			if err := openBrowser(appKeyURL); err != nil {
+				m.notice = fmt.Sprintf("Could not open a browser; visit %s", appKeyURL)
+			} else {
+				m.notice = "Opened " + appKeyURL
+			}
+			return m, nil
+
+		case "ctrl+r":
+			// Toggle token visibility for checking a pasted value
+			if m.inputs[1].EchoMode == textinput.EchoPassword {
+				m.inputs[1].EchoMode = textinput.EchoNormal
+			} else {
+				m.inputs[1].EchoMode = textinput.EchoPassword
+			}
+			return m, nil
 
 		case "tab", "shift+tab", "enter", "up", "down":
 			s := msg.String()
 
 			if s == "enter" && m.focused == len(m.inputs)-1 {
-				return m, tea.Quit
+				apiKey := strings.TrimSpace(m.inputs[0].Value())
+				apiToken := strings.TrimSpace(m.inputs[1].Value())
+				if apiKey == "" || apiToken == "" {
+					m.err = fmt.Errorf("both the API key and token are required")
+					return m, nil
+				}
+
+				m.err = nil
+				m.validating = true
+				return m, tea.Batch(m.spinner.Tick, validateCredentials(apiKey, apiToken))
 			}
 
 			if s == "up" || s == "shift+tab" {
@@ -77,19 +254,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.focused = len(m.inputs) - 1
 			}
 
-			for i := 0; i <= len(m.inputs)-1; i++ {
-				if i == m.focused {
-					cmds[i] = m.inputs[i].Focus()
-					m.inputs[i].PromptStyle = focusedStyle
-					m.inputs[i].TextStyle = focusedStyle
-					continue
-				}
-				m.inputs[i].Blur()
-				m.inputs[i].PromptStyle = noStyle
-				m.inputs[i].TextStyle = noStyle
-			}
-
-			return m, tea.Batch(cmds...)
+			return m, m.focusInputs()
 		}
 	}
 
@@ -100,32 +265,58 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m model) View() string {
+	var status string
+	switch {
+	case m.member != nil:
+		status = successStyle.Render(fmt.Sprintf("✓ Authenticated as %s (@%s)", m.member.FullName, m.member.Username))
+	case m.validating:
+		status = m.spinner.View() + " Checking credentials with Trello..."
+	case m.err != nil:
+		status = errorStyle.Render(fmt.Sprintf("✗ %v", m.err))
+	default:
+		status = continueStyle.Render("Press Enter to continue")
+	}
+
+	help := continueStyle.Render("ctrl+o open " + appKeyURL + " • ctrl+a authorize in browser • ctrl+r show/hide token • esc cancel")
+	if m.notice != "" {
+		help = continueStyle.Render(m.notice) + "\n" + help
+	}
+
 	return fmt.Sprintf(
 		`Trello CLI Configuration
 
 %s
 %s
 
+%s
+
 %s
 `,
 		inputStyle.Width(60).Render("API Key: "+m.inputs[0].View()),
 		inputStyle.Width(60).Render("API Token: "+m.inputs[1].View()),
-		continueStyle.Render("Press Enter to continue"),
+		status,
+		help,
 	) + "\n"
 }
 
-func PromptForConfig() (string, string, string, string, error) {
-	p := tea.NewProgram(initialModel())
+// PromptForConfig asks for API credentials, pre-filled with any known values,
+// and only returns once Trello has accepted them.
+func PromptForConfig(apiKey, apiToken string) (string, string, *trello.Member, error) {
+	p := tea.NewProgram(initialModel(apiKey, apiToken))
 	m, err := p.Run()
 	if err != nil {
-		return "", "", "", "", err
+		return "", "", nil, err
 	}
 
-	if m, ok := m.(model); ok {
-		return m.inputs[0].Value(), m.inputs[1].Value(), "", "", nil
+	m2, ok := m.(model)
+	if !ok {
+		return "", "", nil, fmt.Errorf("unexpected model type")
+	}
+	if m2.cancelled || m2.member == nil {
+		return "", "", nil, errSetupCancelled
 	}
 
-	return "", "", "", "", fmt.Errorf("unexpected model type")
+	return strings.TrimSpace(m2.inputs[0].Value()), strings.TrimSpace(m2.inputs[1].Value()), m2.member, nil
 }
 
 func PromptForOrganization(client *trello.Client) (string, error) {
@@ -138,19 +329,17 @@ func PromptForOrganization(client *trello.Client) (string, error) {
 		return "", fmt.Errorf("no organizations found")
 	}
 
-	fmt.Println("Available Workspaces:")
+	items := make([]pickerItem, len(organizations))
 	for i, org := range organizations {
-		fmt.Printf("%d. %s\n", i+1, org.Name)
+		items[i] = pickerItem{title: org.Name}
 	}
 
-	var choice int
-	fmt.Print("Select workspace (number): ")
-	_, err = fmt.Scanf("%d", &choice)
-	if err != nil || choice < 1 || choice > len(organizations) {
-		return "", fmt.Errorf("invalid choice")
+	choice, err := runPicker("Select workspace", items)
+	if err != nil {
+		return "", err
 	}
 
-	return organizations[choice-1].ID, nil
+	return organizations[choice].ID, nil
 }
 
 func PromptForBoard(client *trello.Client, organizationID string) (string, error) {
@@ -163,17 +352,66 @@ func PromptForBoard(client *trello.Client, organizationID string) (string, error
 		return "", fmt.Errorf("no boards found in this workspace")
 	}
 
-	fmt.Println("Available Boards:")
+	items := make([]pickerItem, len(boards))
 	for i, board := range boards {
-		fmt.Printf("%d. %s\n", i+1, board.Name)
+		items[i] = pickerItem{title: board.Name}
+	}
+
+	choice, err := runPicker("Select board", items)
+	if err != nil {
+		return "", err
+	}
+
+	return boards[choice].ID, nil
+}
+
+func PromptForList(client boardReader, boardID string) (*trello.List, error) {
+	lists, err := client.GetLists(boardID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch lists: %w", err)
+	}
+
+	if len(lists) == 0 {
+		return nil, fmt.Errorf("no lists found on this board")
+	}
+
+	items := make([]pickerItem, len(lists))
+	for i, list := range lists {
+		items[i] = pickerItem{title: list.Name}
+	}
+
+	choice, err := runPicker("Select list", items)
+	if err != nil {
+		return nil, err
+	}
+
+	return &lists[choice], nil
+}
+
+func PromptForMember(client boardReader, boardID string) (*trello.Member, error) {
+	members, err := client.GetBoardMembers(boardID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch board members: %w", err)
+	}
+
+	if len(members) == 0 {
+		return nil, fmt.Errorf("no members found on this board")
 	}
 
-	var choice int
-	fmt.Print("Select board (number): ")
-	_, err = fmt.Scanf("%d", &choice)
-	if err != nil || choice < 1 || choice > len(boards) {
-		return "", fmt.Errorf("invalid choice")
+	choice, err := runPicker("Select member", memberPickerItems(members))
+	if err != nil {
+		return nil, err
 	}
 
-	return boards[choice-1].ID, nil
+	return &members[choice], nil
+}
+
+// memberPickerItems shows members by full name, with the username to tell
+// namesakes apart.
+func memberPickerItems(members []trello.Member) []pickerItem {
+	items := make([]pickerItem, len(members))
+	for i, member := range members {
+		items[i] = pickerItem{title: member.FullName, detail: "@" + member.Username}
+	}
+	return items
 }
@@ -0,0 +1,33 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// ParseError describes a problem in an expression and where it is.
+type ParseError struct {
+	Src string
+	Pos int // byte offset of the problem in Src
+	Msg string
+}
+
+// Column is the 1-based character position of the problem.
+func (e *ParseError) Column() int {
+	return column(e.Src, e.Pos)
+}
+
+func column(src string, pos int) int {
+	return utf8.RuneCountInString(src[:pos]) + 1
+}
+
+// Error reports the message followed by the expression with a caret under
+// the problem.
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s (column %d)\n  %s\n  %s^", e.Msg, e.Column(), e.Src, strings.Repeat(" ", e.Column()-1))
+}
+
+func newError(src string, pos int, format string, args ...interface{}) *ParseError {
+	return &ParseError{Src: src, Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
@@ -0,0 +1,272 @@
+package query
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	offsetPattern = regexp.MustCompile(`^([+-]?\d+)([dw])$`)
+	datePattern   = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+)
+
+// Parse compiles an expression. Errors are *ParseError values that point at
+// the offending part of src.
+//
+//	expr       = or
+//	or         = and { "or" and }
+//	and        = unary { "and" unary }
+//	unary      = "not" unary | "(" expr ")" | comparison
+//	comparison = field op value | field ["not"] "in" "(" value { "," value } ")"
+func Parse(src string) (*Query, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{src: src, tokens: tokens, fields: make(map[string]bool)}
+	if p.peek().kind == tokEOF {
+		return nil, newError(src, 0, "empty expression")
+	}
+
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, p.errorAt(tok, "unexpected %s; expected and, or or the end of the expression", tok)
+	}
+	return &Query{expr: expr, fields: p.fields}, nil
+}
+
+type parser struct {
+	src    string
+	tokens []token
+	pos    int
+	fields map[string]bool
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) errorAt(tok token, format string, args ...interface{}) *ParseError {
+	return newError(p.src, tok.pos, format, args...)
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().is("or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().is("and") {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	tok := p.peek()
+	switch {
+	case tok.is("not"):
+		p.next()
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{inner}, nil
+	case tok.kind == tokLParen:
+		p.next()
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokRParen {
+			return nil, p.errorAt(closing, "expected ) to close the ( at column %d, found %s", column(p.src, tok.pos), closing)
+		}
+		return inner, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (node, error) {
+	tok := p.next()
+	if tok.kind != tokWord {
+		return nil, p.errorAt(tok, "expected a field name, found %s (fields: %s)", tok, strings.Join(Fields(), ", "))
+	}
+	name := strings.ToLower(tok.text)
+	f, ok := fields[name]
+	if !ok {
+		return nil, p.errorAt(tok, "unknown field %q (fields: %s)", tok.text, strings.Join(Fields(), ", "))
+	}
+	p.fields[name] = true
+
+	opTok := p.next()
+	op := opTok.text
+	if opTok.is("not") {
+		if !p.peek().is("in") {
+			return nil, p.errorAt(p.peek(), "expected in after not, found %s", p.peek())
+		}
+		p.next()
+		op = "not in"
+	} else if opTok.is("in") {
+		op = "in"
+	} else if opTok.kind != tokOp {
+		return nil, p.errorAt(opTok, "expected an operator after %s, found %s (%s)", name, opTok, p.operatorHelp(f))
+	}
+
+	if op == "in" || op == "not in" {
+		if f.kind == timeField {
+			return nil, p.errorAt(opTok, "%s does not support %s (%s)", name, op, p.operatorHelp(f))
+		}
+		values, err := p.parseValueList(name, f)
+		if err != nil {
+			return nil, err
+		}
+		return comparison{field: f, op: op, values: values}, nil
+	}
+
+	if !containsOp(operatorsFor[f.kind], op) {
+		return nil, p.errorAt(opTok, "%s does not support %s (%s)", name, op, p.operatorHelp(f))
+	}
+	v, err := p.parseValue(name, f, op)
+	if err != nil {
+		return nil, err
+	}
+	return comparison{field: f, op: op, values: []value{v}}, nil
+}
+
+func (p *parser) operatorHelp(f field) string {
+	ops := append([]string(nil), operatorsFor[f.kind]...)
+	if f.kind != timeField {
+		ops = append(ops, "in", "not in")
+	}
+	return "use " + strings.Join(ops, " ")
+}
+
+func (p *parser) parseValueList(name string, f field) ([]value, error) {
+	if open := p.next(); open.kind != tokLParen {
+		return nil, p.errorAt(open, "expected ( to start the list of values, found %s", open)
+	}
+
+	var values []value
+	for {
+		v, err := p.parseValue(name, f, "=")
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+
+		switch sep := p.next(); sep.kind {
+		case tokComma:
+			continue
+		case tokRParen:
+			return values, nil
+		default:
+			return nil, p.errorAt(sep, "expected , or ) in the list of values, found %s", sep)
+		}
+	}
+}
+
+func (p *parser) parseValue(name string, f field, op string) (value, error) {
+	tok := p.next()
+	if tok.kind != tokWord && tok.kind != tokString {
+		return value{}, p.errorAt(tok, "expected a value for %s, found %s", name, tok)
+	}
+	text := tok.text
+	quoted := tok.kind == tokString
+
+	// none means "no value" wherever it makes sense to ask for that
+	if !quoted && strings.EqualFold(text, "none") && f.kind != numberField {
+		if op != "=" && op != "!=" {
+			return value{}, p.errorAt(tok, "none can only be compared with = or !=")
+		}
+		return value{none: true}, nil
+	}
+
+	switch f.kind {
+	case setField:
+		if name == "member" && !quoted && strings.HasPrefix(text, "@") {
+			if strings.EqualFold(text, "@me") {
+				return value{me: true}, nil
+			}
+			text = text[1:]
+		}
+		return value{text: strings.ToLower(text)}, nil
+
+	case timeField:
+		if quoted {
+			return value{}, p.errorAt(tok, "expected a date for %s such as 2026-10-01, 3d, -2w or today; dates are not quoted", name)
+		}
+		if strings.EqualFold(text, "today") {
+			return value{}, nil
+		}
+		if m := offsetPattern.FindStringSubmatch(text); m != nil {
+			n, err := strconv.Atoi(m[1])
+			if err != nil {
+				return value{}, p.errorAt(tok, "offset %s is out of range", text)
+			}
+			if m[2] == "w" {
+				n *= 7
+			}
+			return value{days: n}, nil
+		}
+		if datePattern.MatchString(text) {
+			date, err := time.Parse("2006-01-02", text)
+			if err != nil {
+				return value{}, p.errorAt(tok, "invalid date %s", text)
+			}
+			return value{date: date}, nil
+		}
+		return value{}, p.errorAt(tok, "expected a date for %s such as 2026-10-01, an offset from today such as 3d or -2w, today or none; found %s", name, tok)
+
+	case numberField:
+		n, err := strconv.Atoi(strings.TrimPrefix(text, "#"))
+		if err != nil || quoted {
+			return value{}, p.errorAt(tok, "expected a card number for %s, found %s", name, tok)
+		}
+		return value{number: n}, nil
+	}
+
+	return value{text: strings.ToLower(text)}, nil
+}
+
+func containsOp(ops []string, op string) bool {
+	for _, o := range ops {
+		if o == op {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,240 @@
+// Package query implements the small expression language used by --where to
+// filter cards locally, e.g.
+//
+//	list in ("Doing", "Review") and label = bug and due < 3d and not member = @bob
+//
+// Expressions combine comparisons with and, or, not and parentheses.
+package query
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// Card is the view of a card that expressions are evaluated against.
+type Card struct {
+	Number  int
+	Title   string
+	Desc    string
+	List    string
+	Board   string
+	Labels  []string  // label names and colors
+	Members []string  // member IDs, usernames and full names
+	Due     time.Time // zero when the card has no due date
+	Created time.Time
+}
+
+// Env holds what expressions are evaluated relative to.
+type Env struct {
+	Now time.Time
+	Me  string // member ID that @me stands for
+}
+
+type fieldKind int
+
+const (
+	textField fieldKind = iota
+	setField
+	timeField
+	numberField
+)
+
+type field struct {
+	kind fieldKind
+	get  func(c *Card) interface{}
+}
+
+var fields = map[string]field{
+	"title":   {textField, func(c *Card) interface{} { return c.Title }},
+	"desc":    {textField, func(c *Card) interface{} { return c.Desc }},
+	"list":    {textField, func(c *Card) interface{} { return c.List }},
+	"board":   {textField, func(c *Card) interface{} { return c.Board }},
+	"label":   {setField, func(c *Card) interface{} { return c.Labels }},
+	"member":  {setField, func(c *Card) interface{} { return c.Members }},
+	"due":     {timeField, func(c *Card) interface{} { return c.Due }},
+	"created": {timeField, func(c *Card) interface{} { return c.Created }},
+	"number":  {numberField, func(c *Card) interface{} { return c.Number }},
+}
+
+// operatorsFor lists the comparisons each kind of field supports, besides
+// in and not in, which text, set and number fields accept.
+var operatorsFor = map[fieldKind][]string{
+	textField:   {"=", "!=", "~", "!~"},
+	setField:    {"=", "!=", "~", "!~"},
+	timeField:   {"=", "!=", "<", "<=", ">", ">="},
+	numberField: {"=", "!=", "<", "<=", ">", ">="},
+}
+
+// Fields lists the field names expressions can use.
+func Fields() []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Query is a parsed expression.
+type Query struct {
+	expr   node
+	fields map[string]bool
+}
+
+// Uses reports whether the expression refers to the named field, so callers
+// can skip fetching data nobody asked about.
+func (q *Query) Uses(name string) bool {
+	return q.fields[name]
+}
+
+// Match reports whether the card satisfies the expression.
+func (q *Query) Match(c *Card, env *Env) bool {
+	return q.expr.eval(c, env)
+}
+
+type node interface {
+	eval(c *Card, env *Env) bool
+}
+
+type andNode struct{ left, right node }
+type orNode struct{ left, right node }
+type notNode struct{ inner node }
+
+func (n andNode) eval(c *Card, env *Env) bool { return n.left.eval(c, env) && n.right.eval(c, env) }
+func (n orNode) eval(c *Card, env *Env) bool  { return n.left.eval(c, env) || n.right.eval(c, env) }
+func (n notNode) eval(c *Card, env *Env) bool { return !n.inner.eval(c, env) }
+
+// value is a literal on the right-hand side of a comparison.
+type value struct {
+	text   string // lower-cased, for text and set fields
+	me     bool   // @me
+	none   bool   // none: no value at all
+	number int
+	days   int       // offset from today, for relative dates
+	date   time.Time // absolute date; zero for relative ones
+}
+
+type comparison struct {
+	field  field
+	op     string // an operator, "in" or "not in"
+	values []value
+}
+
+func (n comparison) eval(c *Card, env *Env) bool {
+	if n.op == "in" || n.op == "not in" {
+		matched := false
+		for _, v := range n.values {
+			if compare(n.field, "=", v, c, env) {
+				matched = true
+				break
+			}
+		}
+		return matched == (n.op == "in")
+	}
+	return compare(n.field, n.op, n.values[0], c, env)
+}
+
+func compare(f field, op string, v value, c *Card, env *Env) bool {
+	switch f.kind {
+	case textField:
+		return compareText(op, f.get(c).(string), v)
+	case setField:
+		return compareSet(op, f.get(c).([]string), v, env)
+	case timeField:
+		return compareTime(op, f.get(c).(time.Time), v, env)
+	}
+	return compareOrdered(op, f.get(c).(int), v.number)
+}
+
+func compareText(op, actual string, v value) bool {
+	actual = strings.ToLower(actual)
+	switch op {
+	case "=":
+		return actual == v.text || (v.none && actual == "")
+	case "!=":
+		return !compareText("=", actual, v)
+	case "~":
+		return strings.Contains(actual, v.text)
+	case "!~":
+		return !strings.Contains(actual, v.text)
+	}
+	return false
+}
+
+// compareSet treats = as "has" and ~ as "has one containing".
+func compareSet(op string, actual []string, v value, env *Env) bool {
+	has := func(match func(string) bool) bool {
+		for _, a := range actual {
+			if match(strings.ToLower(a)) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch op {
+	case "=":
+		switch {
+		case v.none:
+			return len(actual) == 0
+		case v.me:
+			me := strings.ToLower(env.Me)
+			return has(func(a string) bool { return a == me })
+		}
+		return has(func(a string) bool { return a == v.text })
+	case "!=":
+		return !compareSet("=", actual, v, env)
+	case "~":
+		return has(func(a string) bool { return strings.Contains(a, v.text) })
+	case "!~":
+		return !compareSet("~", actual, v, env)
+	}
+	return false
+}
+
+// compareTime compares calendar days in the time zone of env.Now. A card
+// without the date only matches "= none" and "!= <date>".
+func compareTime(op string, actual time.Time, v value, env *Env) bool {
+	if v.none || actual.IsZero() {
+		equal := v.none && actual.IsZero()
+		if op == "!=" {
+			return !equal
+		}
+		return op == "=" && equal
+	}
+
+	loc := env.Now.Location()
+	target := dayNumber(env.Now, loc) + v.days
+	if !v.date.IsZero() {
+		target = dayNumber(v.date, time.UTC)
+	}
+	return compareOrdered(op, dayNumber(actual, loc), target)
+}
+
+func compareOrdered(op string, a, b int) bool {
+	switch op {
+	case "=":
+		return a == b
+	case "!=":
+		return a != b
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	}
+	return false
+}
+
+// dayNumber numbers t's calendar date in loc, counting days since the Unix
+// epoch. Dates are compared by these numbers rather than by subtracting
+// times, so a day shortened or lengthened by a DST change still counts as
+// one day.
+func dayNumber(t time.Time, loc *time.Location) int {
+	y, m, d := t.In(loc).Date()
+	return int(time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Unix() / (24 * 60 * 60))
+}
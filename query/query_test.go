@@ -0,0 +1,112 @@
+package query
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestMatch(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	utc := func(s string) time.Time {
+		v, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+
+	// Clocks go forward on 2026-03-08 and back on 2026-11-01 in New York
+	beforeSpring := &Env{Now: time.Date(2026, 3, 6, 10, 0, 0, 0, newYork), Me: "m1"}
+	beforeFall := &Env{Now: time.Date(2026, 10, 31, 10, 0, 0, 0, newYork), Me: "m1"}
+
+	tests := []struct {
+		name string
+		expr string
+		env  *Env
+		card Card
+		want bool
+	}{
+		// Dates, across DST changes
+		{"within 3 days across spring forward", "due < 3d", beforeSpring, Card{Due: utc("2026-03-08T16:00:00Z")}, true},
+		{"exactly 2 days across spring forward", "due = 2d", beforeSpring, Card{Due: utc("2026-03-08T16:00:00Z")}, true},
+		{"late evening stays on its local day", "due = 2d", beforeSpring, Card{Due: utc("2026-03-09T03:30:00Z")}, true},
+		{"past the offset", "due < 2d", beforeSpring, Card{Due: utc("2026-03-09T16:00:00Z")}, false},
+		{"exactly 2 days across fall back", "due = 2d", beforeFall, Card{Due: utc("2026-11-02T15:00:00Z")}, true},
+		{"within 3 days across fall back", "due < 3d", beforeFall, Card{Due: utc("2026-11-02T15:00:00Z")}, true},
+		{"weeks", "due <= 1w", beforeFall, Card{Due: utc("2026-11-07T15:00:00Z")}, true},
+		{"negative offset", "due < -1d", beforeSpring, Card{Due: utc("2026-03-04T15:00:00Z")}, true},
+		{"today", "due = today", beforeSpring, Card{Due: utc("2026-03-06T23:00:00Z")}, true},
+		{"absolute date", "due = 2026-03-08", beforeSpring, Card{Due: utc("2026-03-08T16:00:00Z")}, true},
+		{"absolute date before", "due < 2026-03-08", beforeSpring, Card{Due: utc("2026-03-08T16:00:00Z")}, false},
+		{"created", "created >= -7d", beforeSpring, Card{Created: utc("2026-03-01T12:00:00Z")}, true},
+
+		// none
+		{"no due date is none", "due = none", beforeSpring, Card{}, true},
+		{"due date is not none", "due = none", beforeSpring, Card{Due: utc("2026-03-08T16:00:00Z")}, false},
+		{"due date is != none", "due != none", beforeSpring, Card{Due: utc("2026-03-08T16:00:00Z")}, true},
+		{"no due date never compares", "due < 3d", beforeSpring, Card{}, false},
+		{"no due date is != a date", "due != 2026-03-08", beforeSpring, Card{}, true},
+		{"no labels", "label = none", beforeSpring, Card{}, true},
+		{"some labels", "label != none", beforeSpring, Card{Labels: []string{"bug"}}, true},
+
+		// in and not in
+		{"list in", "list in (Doing, Review)", beforeSpring, Card{List: "Review"}, true},
+		{"list in is case-insensitive", "list in (doing, review)", beforeSpring, Card{List: "Review"}, true},
+		{"list not in", "list not in (Doing, Review)", beforeSpring, Card{List: "Done"}, true},
+		{"list not in excluded", "list not in (Doing, Review)", beforeSpring, Card{List: "Doing"}, false},
+		{"label in", "label in (bug, ui)", beforeSpring, Card{Labels: []string{"backend", "ui"}}, true},
+		{"label in no match", "label in (bug, ui)", beforeSpring, Card{Labels: []string{"backend"}}, false},
+
+		// @me
+		{"@me by member ID", "member = @me", beforeSpring, Card{Members: []string{"m2", "m1"}}, true},
+		{"@me not a member", "member = @me", beforeSpring, Card{Members: []string{"m2"}}, false},
+		{"not @me", "member != @me", beforeSpring, Card{Members: []string{"m2"}}, true},
+		{"@me in a list", "member in (@me, alice)", beforeSpring, Card{Members: []string{"m1"}}, true},
+		{"other members by name", "member = @alice", beforeSpring, Card{Members: []string{"m2", "alice", "Alice Doe"}}, true},
+
+		// Combinations
+		{"and", "list = Doing and label = bug", beforeSpring, Card{List: "Doing", Labels: []string{"bug"}}, true},
+		{"or", "list = Done or label = bug", beforeSpring, Card{List: "Doing", Labels: []string{"bug"}}, true},
+		{"not", "not list = Done", beforeSpring, Card{List: "Done"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.expr, err)
+			}
+			if got := q.Match(&tt.card, tt.env); got != tt.want {
+				t.Errorf("%q matched %v, want %v", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		expr   string
+		column int
+	}{
+		{"due < soon", 7},
+		{"colour = red", 1},
+		{"list in (Doing", 15},
+		{"title < x", 7},
+	}
+
+	for _, tt := range tests {
+		_, err := Parse(tt.expr)
+		perr, ok := err.(*ParseError)
+		if !ok {
+			t.Errorf("Parse(%q) returned %v, want a *ParseError", tt.expr, err)
+			continue
+		}
+		if perr.Column() != tt.column {
+			t.Errorf("Parse(%q) error at column %d, want %d: %v", tt.expr, perr.Column(), tt.column, perr)
+		}
+	}
+}
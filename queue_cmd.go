@@ -0,0 +1,229 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"trello_cli/store"
+	"trello_cli/trello"
+
+	"golang.org/x/term"
+)
+
+const queueUsage = `Usage: trello_cli queue [command]
+
+Commands:
+  list         Show changes waiting to be sent (default)
+  push         Send queued changes to Trello in order
+  drop <n>     Discard queued change n
+`
+
+func runQueueCommand(args []string) {
+	command := "list"
+	if len(args) > 0 {
+		command = args[0]
+	}
+
+	switch command {
+	case "list":
+		ops, err := store.LoadQueue()
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(ops) == 0 {
+			fmt.Println("No queued changes.")
+			return
+		}
+		for _, op := range ops {
+			fmt.Printf("%3d  %-14s  %s\n", op.Seq, formatAge(op.Age()), op.Describe())
+		}
+	case "push":
+		_, client := mustLoadClient()
+		if pending := replayQueue(client); pending > 0 {
+			os.Exit(1)
+		}
+	case "drop":
+		if len(args) != 2 {
+			fmt.Fprint(os.Stderr, queueUsage)
+			os.Exit(2)
+		}
+		dropQueued(args[1])
+	case "help", "-h", "--help":
+		fmt.Print(queueUsage)
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown queue command: %s\n\n%s", command, queueUsage)
+		os.Exit(2)
+	}
+}
+
+func dropQueued(arg string) {
+	seq, err := strconv.Atoi(arg)
+	if err != nil {
+		log.Fatalf("Invalid change number %q", arg)
+	}
+
+	ops, err := store.LoadQueue()
+	if err != nil {
+		log.Fatal(err)
+	}
+	for i, op := range ops {
+		if op.Seq == seq {
+			if err := store.SaveQueue(append(ops[:i:i], ops[i+1:]...)); err != nil {
+				log.Fatal(err)
+			}
+			fmt.Printf("Dropped change %d: %s\n", seq, op.Describe())
+			return
+		}
+	}
+	log.Fatalf("No queued change %d", seq)
+}
+
+// replayQueue sends queued changes to Trello in the order they were made and
+// returns how many are still pending. Replay stops at the first change that
+// cannot be sent, so later changes never overtake it. Each change is removed
+// from the journal as soon as it is sent.
+func replayQueue(client *trello.Client) int {
+	ops, err := store.LoadQueue()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(ops) == 0 {
+		return 0
+	}
+
+	// Conflict checks must see Trello as it is now, not a cached list of
+	// lists from before someone archived one
+	client.Revalidate()
+
+	fmt.Fprintf(os.Stderr, "Sending %d queued change(s)...\n", len(ops))
+	sent, skipped := 0, 0
+	for len(ops) > 0 {
+		op := ops[0]
+
+		conflict, err := checkConflict(client, op)
+		if err != nil {
+			reportStopped(op, err, len(ops), false)
+			return len(ops)
+		}
+		if conflict != "" {
+			fmt.Fprintf(os.Stderr, "Conflict in change %d (%s): %s\n", op.Seq, op.Describe(), conflict)
+			switch resolveConflict(op) {
+			case conflictSkip:
+				skipped++
+				ops = ops[1:]
+				if err := store.SaveQueue(ops); err != nil {
+					log.Fatal(err)
+				}
+				continue
+			case conflictKeep:
+				fmt.Fprintf(os.Stderr, "%d change(s) left in the queue; see 'trello_cli queue'.\n", len(ops))
+				return len(ops)
+			}
+		}
+
+		result, err := applyOp(client, op)
+		if err != nil {
+			reportStopped(op, err, len(ops), true)
+			return len(ops)
+		}
+		fmt.Fprintln(os.Stderr, result)
+		sent++
+
+		ops = ops[1:]
+		if err := store.SaveQueue(ops); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "Queue empty: %d sent, %d skipped.\n", sent, skipped)
+	return 0
+}
+
+// reportStopped explains why replay stopped at op. sent tells whether op
+// itself was being sent, as opposed to checked for conflicts.
+func reportStopped(op store.Op, err error, pending int, sent bool) {
+	if (!sent && isNetworkError(err)) || canQueue(op, err) {
+		fmt.Fprintf(os.Stderr, "Trello is still unreachable; %d change(s) stay queued.\n", pending)
+		return
+	}
+	if isNetworkError(err) {
+		fmt.Fprintf(os.Stderr, "Change %d (%s) may or may not have reached Trello: %v\n", op.Seq, op.Describe(), err)
+		fmt.Fprintf(os.Stderr, "%d change(s) stay queued. Check the board, then drop it with 'trello_cli queue drop %d' or push again.\n", pending, op.Seq)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Change %d (%s) failed: %v\n", op.Seq, op.Describe(), err)
+	fmt.Fprintf(os.Stderr, "%d change(s) stay queued. Drop it with 'trello_cli queue drop %d' to continue.\n", pending, op.Seq)
+}
+
+// checkConflict compares the card as it is now with how it was when the
+// change was queued, and describes anything that changed in between.
+func checkConflict(client *trello.Client, op store.Op) (string, error) {
+	if op.Kind == store.OpCreate {
+		lists, err := client.GetLists(op.BoardID)
+		if err != nil {
+			return "", err
+		}
+		for _, list := range lists {
+			if list.ID == op.ListID {
+				return "", nil
+			}
+		}
+		return fmt.Sprintf("list %s was archived or deleted", op.ListName), nil
+	}
+
+	card, err := client.GetCardDetails(op.CardID)
+	if trello.IsNotFound(err) {
+		return "the card was deleted", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if card.Closed {
+		return "the card was archived", nil
+	}
+
+	if op.Kind == store.OpMove && card.IDList != op.FromListID && card.IDList != op.ListID {
+		now := "another list"
+		if card.List != nil {
+			now = card.List.Name
+		}
+		return fmt.Sprintf("the card was moved from %s to %s meanwhile", op.FromListName, now), nil
+	}
+	return "", nil
+}
+
+type conflictChoice int
+
+const (
+	conflictApply conflictChoice = iota
+	conflictSkip
+	conflictKeep
+)
+
+// resolveConflict asks what to do about a conflicting change. Without a
+// terminal to ask on, the change is kept for later.
+func resolveConflict(op store.Op) conflictChoice {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return conflictKeep
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		fmt.Fprint(os.Stderr, "[a]pply anyway, [s]kip (discard it), or [k]eep it queued? [k] ")
+		answer, err := reader.ReadString('\n')
+		if err != nil {
+			return conflictKeep
+		}
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "a", "apply":
+			return conflictApply
+		case "s", "skip":
+			return conflictSkip
+		case "", "k", "keep":
+			return conflictKeep
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"trello_cli/trello"
+)
+
+var (
+	cardURLPattern   = regexp.MustCompile(`trello\.com/c/([0-9A-Za-z]+)`)
+	shortLinkPattern = regexp.MustCompile(`^[0-9A-Za-z]{8}$`)
+	cardIDPattern    = regexp.MustCompile(`^[0-9a-fA-F]{24}$`)
+)
+
+// boardReader is the read side of the Trello API used by the listing and
+// card lookups. It is satisfied by *trello.Client and by an offline
+// *store.Snapshot.
+type boardReader interface {
+	GetMemberID() (string, error)
+	GetCards(boardID string) ([]trello.Card, error)
+	GetLists(boardID string) ([]trello.List, error)
+	GetBoardMembers(boardID string) ([]trello.Member, error)
+	GetCardDetails(cardID string) (*trello.DetailedCard, error)
+	GetBoardCard(boardID string, idShort int) (*trello.DetailedCard, error)
+}
+
+// isExplicitCardRef reports whether ref can only be a card reference: a
+// card number, URL or ID, as opposed to a short link or title text, which
+// could also be an ordinary word.
+func isExplicitCardRef(ref string) bool {
+	ref = strings.TrimSpace(ref)
+	if _, err := strconv.Atoi(strings.TrimPrefix(ref, "#")); err == nil {
+		return true
+	}
+	return cardURLPattern.MatchString(ref) || cardIDPattern.MatchString(ref)
+}
+
+// resolveCard finds the card a user refers to on the command line. It accepts
+// #123 or 123 (idShort on the board), a card URL, a bare short link, a full
+// card ID, or part of the title. Title matches that are ambiguous are
+// resolved interactively when a terminal is available.
+func resolveCard(client boardReader, boardID, ref string) (*trello.DetailedCard, error) {
+	ref = strings.TrimSpace(ref)
+	if isExplicitCardRef(ref) {
+		return lookupCard(client, boardID, ref)
+	}
+
+	// Eight characters could be a short link or a word from the title
+	if shortLinkPattern.MatchString(ref) {
+		card, err := client.GetCardDetails(ref)
+		if err == nil {
+			return onBoard(card, boardID, ref)
+		}
+		if !trello.IsNotFound(err) {
+			return nil, err
+		}
+	}
+
+	return resolveTitle(client, boardID, ref)
+}
+
+// lookupCard finds a card by number, URL, short link or ID only. Unlike
+// resolveCard it never searches titles, so it never prompts.
+func lookupCard(client boardReader, boardID, ref string) (*trello.DetailedCard, error) {
+	ref = strings.TrimSpace(ref)
+
+	// Card URLs carry the short link, which can be fetched directly
+	if m := cardURLPattern.FindStringSubmatch(ref); m != nil {
+		card, err := client.GetCardDetails(m[1])
+		if trello.IsNotFound(err) {
+			return nil, fmt.Errorf("card %s not found", ref)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return onBoard(card, boardID, ref)
+	}
+
+	if idShort, err := strconv.Atoi(strings.TrimPrefix(ref, "#")); err == nil {
+		return resolveIDShort(client, boardID, idShort)
+	}
+
+	if cardIDPattern.MatchString(ref) || shortLinkPattern.MatchString(ref) {
+		card, err := client.GetCardDetails(ref)
+		if trello.IsNotFound(err) {
+			return nil, fmt.Errorf("card %s not found", ref)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return onBoard(card, boardID, ref)
+	}
+
+	return nil, fmt.Errorf("%q is not a card number, URL, short link or ID", ref)
+}
+
+// onBoard rejects a card found by URL, short link or ID that belongs to
+// another board, since everything else works on the configured board.
+func onBoard(card *trello.DetailedCard, boardID, ref string) (*trello.DetailedCard, error) {
+	if boardID != "" && card.IDBoard != "" && card.IDBoard != boardID {
+		return nil, fmt.Errorf("card %s is on another board; switch to it with 'trello_cli config switch-board'", ref)
+	}
+	return card, nil
+}
+
+func resolveIDShort(client boardReader, boardID string, idShort int) (*trello.DetailedCard, error) {
+	card, err := client.GetBoardCard(boardID, idShort)
+	if trello.IsNotFound(err) {
+		return nil, fmt.Errorf("card with ID #%d not found on this board", idShort)
+	}
+	return card, err
+}
+
+func resolveTitle(client boardReader, boardID, text string) (*trello.DetailedCard, error) {
+	cards, err := client.GetCards(boardID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cards: %w", err)
+	}
+
+	needle := strings.ToLower(text)
+	var matches []trello.Card
+	for _, card := range cards {
+		if strings.Contains(strings.ToLower(card.Name), needle) {
+			matches = append(matches, card)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no card on this board matches %q", text)
+	case 1:
+		return client.GetCardDetails(matches[0].ID)
+	}
+
+	lists, err := client.GetLists(boardID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lists: %w", err)
+	}
+	listMap := make(map[string]string)
+	for _, list := range lists {
+		listMap[list.ID] = list.Name
+	}
+
+	card, err := pickCard(fmt.Sprintf("%d cards match %q", len(matches), text), matches, listMap)
+	if errors.Is(err, errPickerCancelled) {
+		return nil, err
+	}
+	if err != nil {
+		// Without a terminal, list the candidates so the user can be specific
+		var b strings.Builder
+		fmt.Fprintf(&b, "%q matches %d cards; use the card number instead:", text, len(matches))
+		for _, match := range matches {
+			fmt.Fprintf(&b, "\n  #%d %s", match.IDShort, match.Name)
+		}
+		return nil, fmt.Errorf("%s", b.String())
+	}
+
+	return client.GetCardDetails(card.ID)
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"trello_cli/trello"
+)
+
+// maxSearchResults is the most cards Trello returns for one search.
+const maxSearchResults = 1000
+
+func runSearchCommand(args []string) {
+	fs := flag.NewFlagSet("search", flag.ExitOnError)
+	workspace := fs.Bool("workspace", false, "Search every board in the configured workspace")
+	allWorkspaces := fs.Bool("all-workspaces", false, "Search every board you can see")
+	limit := fs.Int("limit", 50, fmt.Sprintf("Maximum number of results (at most %d)", maxSearchResults))
+	output := fs.String("output", "table", "Output format: "+outputFormats)
+	fs.StringVar(output, "o", "table", "Output format (short)")
+	columnSpec := fs.String("columns", "", "Columns to show (comma-separated): "+columnNames)
+	addClientFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: trello_cli search [flags] <query>\n\n")
+		fmt.Fprintf(fs.Output(), "The query uses Trello's search syntax, e.g. 'login label:bug @me is:open due:week'.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	query := strings.TrimSpace(strings.Join(fs.Args(), " "))
+	if query == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+	if err := checkOutputFormat(*output); err != nil {
+		log.Fatal(err)
+	}
+	multiBoard := *workspace || *allWorkspaces
+	columns, err := parseColumns(*columnSpec, *output, multiBoard)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *limit < 1 || *limit > maxSearchResults {
+		log.Fatalf("--limit must be between 1 and %d", maxSearchResults)
+	}
+
+	cfg, client := mustLoadClient()
+
+	opts := trello.SearchOptions{Limit: *limit}
+	switch {
+	case *allWorkspaces:
+	case *workspace:
+		if cfg.Workspace == "" {
+			log.Fatalf("No workspace selected. Run 'trello_cli config switch-board' to choose one.")
+		}
+		opts.OrganizationIDs = []string{cfg.Workspace}
+	default:
+		opts.BoardIDs = []string{cfg.BoardID}
+	}
+
+	results, err := client.Search(query, opts)
+	if err != nil {
+		log.Fatalf("Search failed: %v", err)
+	}
+
+	// Keep Trello's relevance order rather than sorting by list
+	rows := make([]cardRow, len(results))
+	for i, result := range results {
+		rows[i] = cardRow{card: result.Card, listName: "Unknown"}
+		if result.List != nil {
+			rows[i].listName = result.List.Name
+		}
+		if result.Board != nil {
+			rows[i].boardName = result.Board.Name
+		}
+	}
+
+	if usesColumn(columns, "members") {
+		resolveRowMembers(rows, client)
+	}
+	if err := writeRows(rows, *output, columns); err != nil {
+		log.Fatal(err)
+	}
+	if len(rows) == 0 && *output == "table" {
+		fmt.Fprintln(os.Stderr, "No cards found.")
+	}
+}
@@ -0,0 +1,188 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+	"trello_cli/config"
+)
+
+// Kinds of queued operations.
+const (
+	OpCreate  = "create"
+	OpComment = "comment"
+	OpMove    = "move"
+)
+
+// Op is a change made while offline, waiting to be sent to Trello. Besides
+// the change itself it records what the card looked like when the change was
+// made, so replay can tell whether someone else changed it meanwhile.
+type Op struct {
+	Seq      int       `json:"seq"`
+	Kind     string    `json:"kind"`
+	QueuedAt time.Time `json:"queued_at"`
+	BoardID  string    `json:"board_id"`
+
+	// The card being changed (comment, move)
+	CardID    string `json:"card_id,omitempty"`
+	CardShort int    `json:"card_short,omitempty"`
+	CardName  string `json:"card_name,omitempty"`
+
+	// The target list (create, move) and, for moves, where the card was
+	ListID       string `json:"list_id,omitempty"`
+	ListName     string `json:"list_name,omitempty"`
+	FromListID   string `json:"from_list_id,omitempty"`
+	FromListName string `json:"from_list_name,omitempty"`
+
+	Name string `json:"name,omitempty"` // title of a new card
+	Desc string `json:"desc,omitempty"` // description of a new card
+	Text string `json:"text,omitempty"` // comment text
+
+	// The member a new card is assigned to, if any
+	MemberID       string `json:"member_id,omitempty"`
+	MemberUsername string `json:"member_username,omitempty"`
+}
+
+// Describe summarizes the operation for reports and prompts.
+func (op Op) Describe() string {
+	switch op.Kind {
+	case OpCreate:
+		if op.MemberUsername != "" {
+			return fmt.Sprintf("create %q in %s for @%s", op.Name, op.ListName, op.MemberUsername)
+		}
+		return fmt.Sprintf("create %q in %s", op.Name, op.ListName)
+	case OpComment:
+		return fmt.Sprintf("comment on #%d %s", op.CardShort, op.CardName)
+	case OpMove:
+		return fmt.Sprintf("move #%d %s from %s to %s", op.CardShort, op.CardName, op.FromListName, op.ListName)
+	}
+	return op.Kind
+}
+
+// Idempotent reports whether sending the operation twice has the same effect
+// as sending it once. Creates and comments would be repeated.
+func (op Op) Idempotent() bool {
+	return op.Kind == OpMove
+}
+
+// Age reports how long ago the change was queued.
+func (op Op) Age() time.Duration {
+	return time.Since(op.QueuedAt)
+}
+
+// JournalPath returns where queued operations are stored.
+func JournalPath() (string, error) {
+	dir, err := config.DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "queue.jsonl"), nil
+}
+
+// LoadQueue returns the queued operations in the order they were made.
+func LoadQueue() ([]Op, error) {
+	path, err := JournalPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read queue: %w", err)
+	}
+
+	var ops []Op
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, 1<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var op Op
+		if err := json.Unmarshal(scanner.Bytes(), &op); err != nil {
+			return nil, fmt.Errorf("failed to parse queue %s line %d: %w", path, line, err)
+		}
+		ops = append(ops, op)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read queue: %w", err)
+	}
+	return ops, nil
+}
+
+// Enqueue appends op to the journal and syncs it to disk before returning,
+// so a queued change survives a crash. It assigns op.Seq and op.QueuedAt.
+func Enqueue(op *Op) error {
+	ops, err := LoadQueue()
+	if err != nil {
+		return err
+	}
+	op.Seq = 1
+	if len(ops) > 0 {
+		op.Seq = ops[len(ops)-1].Seq + 1
+	}
+	op.QueuedAt = time.Now()
+
+	path, err := JournalPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	line, err := json.Marshal(op)
+	if err != nil {
+		return fmt.Errorf("failed to marshal queued change: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open queue: %w", err)
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write queue: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write queue: %w", err)
+	}
+	return f.Close()
+}
+
+// SaveQueue replaces the journal with ops, e.g. after some were replayed.
+func SaveQueue(ops []Op) error {
+	path, err := JournalPath()
+	if err != nil {
+		return err
+	}
+	if len(ops) == 0 {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to clear queue: %w", err)
+		}
+		return nil
+	}
+
+	var buf bytes.Buffer
+	for _, op := range ops {
+		line, err := json.Marshal(op)
+		if err != nil {
+			return fmt.Errorf("failed to marshal queued change: %w", err)
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	if err := config.WriteFileAtomic(path, buf.Bytes(), 0600); err != nil {
+		return fmt.Errorf("failed to write queue: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// useTempData points the journal at a fresh, empty data directory.
+func useTempData(t *testing.T) string {
+	t.Helper()
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	path, err := JournalPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestEnqueue(t *testing.T) {
+	useTempData(t)
+
+	before := time.Now()
+	ops := []Op{
+		{Kind: OpCreate, Name: "First", ListName: "Todo"},
+		{Kind: OpComment, CardShort: 4, Text: "hi"},
+		{Kind: OpMove, CardShort: 4, ListName: "Done"},
+	}
+	for i := range ops {
+		if err := Enqueue(&ops[i]); err != nil {
+			t.Fatal(err)
+		}
+		if ops[i].Seq != i+1 {
+			t.Errorf("op %d got Seq %d, want %d", i, ops[i].Seq, i+1)
+		}
+		if ops[i].QueuedAt.Before(before) {
+			t.Errorf("op %d QueuedAt %v is before the test started", i, ops[i].QueuedAt)
+		}
+	}
+
+	loaded, err := LoadQueue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded) != len(ops) {
+		t.Fatalf("loaded %d ops, want %d", len(loaded), len(ops))
+	}
+	for i, op := range loaded {
+		if op.Seq != ops[i].Seq || op.Kind != ops[i].Kind || !op.QueuedAt.Equal(ops[i].QueuedAt) {
+			t.Errorf("op %d loaded as %+v, want %+v", i, op, ops[i])
+		}
+	}
+}
+
+func TestEnqueueContinuesAfterLastSeq(t *testing.T) {
+	useTempData(t)
+
+	// Replayed ops leave gaps; numbering continues after the last one left
+	if err := SaveQueue([]Op{{Seq: 3, Kind: OpMove}, {Seq: 7, Kind: OpMove}}); err != nil {
+		t.Fatal(err)
+	}
+	op := Op{Kind: OpComment}
+	if err := Enqueue(&op); err != nil {
+		t.Fatal(err)
+	}
+	if op.Seq != 8 {
+		t.Errorf("Seq = %d, want 8", op.Seq)
+	}
+}
+
+func TestLoadQueue(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string // "" leaves the journal missing
+		seqs    []int
+		wantErr string
+	}{
+		{"missing", "", nil, ""},
+		{"in order", `{"seq":1,"kind":"move"}` + "\n" + `{"seq":2,"kind":"comment"}` + "\n", []int{1, 2}, ""},
+		{"blank lines", "\n" + `{"seq":1,"kind":"move"}` + "\n  \n" + `{"seq":2,"kind":"move"}` + "\n\n", []int{1, 2}, ""},
+		{"no final newline", `{"seq":5,"kind":"move"}`, []int{5}, ""},
+		{"corrupt line", `{"seq":1,"kind":"move"}` + "\n\n" + `{"seq":2,` + "\n", nil, "line 3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := useTempData(t)
+			if tt.data != "" {
+				if err := os.WriteFile(path, []byte(tt.data), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			ops, err := LoadQueue()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadQueue error = %v, want one mentioning %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var seqs []int
+			for _, op := range ops {
+				seqs = append(seqs, op.Seq)
+			}
+			if !reflect.DeepEqual(seqs, tt.seqs) {
+				t.Errorf("loaded seqs %v, want %v", seqs, tt.seqs)
+			}
+		})
+	}
+}
+
+func TestSaveQueue(t *testing.T) {
+	path := useTempData(t)
+
+	ops := []Op{{
+		Seq:            2,
+		Kind:           OpCreate,
+		QueuedAt:       time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC),
+		BoardID:        "B1",
+		ListID:         "L1",
+		ListName:       "Todo",
+		Name:           "Write docs",
+		Desc:           "line one\nline two",
+		MemberID:       "M1",
+		MemberUsername: "ann",
+	}}
+	if err := SaveQueue(ops); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadQueue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded, ops) {
+		t.Errorf("round trip gave %+v, want %+v", loaded, ops)
+	}
+
+	// Saving nothing removes the journal, and does so twice without error
+	for i := 0; i < 2; i++ {
+		if err := SaveQueue(nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("journal still exists after saving an empty queue: %v", err)
+	}
+}
+
+func TestOpDescribe(t *testing.T) {
+	tests := []struct {
+		op   Op
+		want string
+	}{
+		{Op{Kind: OpCreate, Name: "Write docs", ListName: "Todo"}, `create "Write docs" in Todo`},
+		{Op{Kind: OpCreate, Name: "Write docs", ListName: "Todo", MemberUsername: "ann"}, `create "Write docs" in Todo for @ann`},
+		{Op{Kind: OpComment, CardShort: 12, CardName: "Fix login"}, "comment on #12 Fix login"},
+		{Op{Kind: OpMove, CardShort: 12, CardName: "Fix login", FromListName: "Doing", ListName: "Done"}, "move #12 Fix login from Doing to Done"},
+		{Op{Kind: "archive"}, "archive"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.op.Describe(); got != tt.want {
+			t.Errorf("Describe() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestOpIdempotent(t *testing.T) {
+	tests := []struct {
+		kind string
+		want bool
+	}{
+		{OpMove, true},
+		{OpCreate, false},
+		{OpComment, false},
+	}
+
+	for _, tt := range tests {
+		if got := (Op{Kind: tt.kind}).Idempotent(); got != tt.want {
+			t.Errorf("Op{Kind: %q}.Idempotent() = %v, want %v", tt.kind, got, tt.want)
+		}
+	}
+}
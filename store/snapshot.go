@@ -0,0 +1,197 @@
+// Package store keeps board data on disk so the CLI can work without a
+// connection to Trello.
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+	"trello_cli/config"
+	"trello_cli/trello"
+)
+
+// SnapshotVersion is the snapshot format written by this build. Snapshots
+// are a cache of Trello data, so older versions are discarded rather than
+// migrated.
+const SnapshotVersion = 1
+
+// ErrNoSnapshot is returned when the board has never been synced.
+var ErrNoSnapshot = errors.New("no offline snapshot for this board; run 'trello_cli sync' first")
+
+// Snapshot is a copy of a board as of SyncedAt.
+type Snapshot struct {
+	Version    int                `json:"version"`
+	SyncedAt   time.Time          `json:"synced_at"`
+	Board      trello.Board       `json:"board"`
+	MemberID   string             `json:"member_id"` // the member who synced
+	Lists      []trello.List      `json:"lists"`
+	Labels     []trello.Label     `json:"labels"`
+	Members    []trello.Member    `json:"members"`
+	Cards      []trello.Card      `json:"cards"`
+	Checklists []trello.Checklist `json:"checklists"`
+	Comments   []trello.Comment   `json:"comments"` // newest first
+}
+
+// SnapshotPath returns where the snapshot of a board is stored.
+func SnapshotPath(boardID string) (string, error) {
+	dir, err := config.DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "snapshots", boardID+".json"), nil
+}
+
+// LoadSnapshot reads the stored snapshot of a board.
+func LoadSnapshot(boardID string) (*Snapshot, error) {
+	path, err := SnapshotPath(boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNoSnapshot
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	var snap Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot %s: %w", path, err)
+	}
+	if snap.Version != SnapshotVersion {
+		return nil, fmt.Errorf("snapshot %s was written by a different version of trello_cli; run 'trello_cli sync' again", path)
+	}
+	return &snap, nil
+}
+
+// SaveSnapshot replaces the stored snapshot of snap.Board.
+func SaveSnapshot(snap *Snapshot) error {
+	path, err := SnapshotPath(snap.Board.ID)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+
+	snap.Version = SnapshotVersion
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return fmt.Errorf("failed to marshal snapshot: %w", err)
+	}
+
+	// Board contents can be private, so keep them as private as the token
+	if err := config.WriteFileAtomic(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return nil
+}
+
+// Age reports how long ago the snapshot was taken.
+func (s *Snapshot) Age() time.Duration {
+	return time.Since(s.SyncedAt)
+}
+
+// notFound mirrors the API's answer for unknown objects, so callers can use
+// trello.IsNotFound for both sources.
+func notFound() error {
+	return &trello.APIError{StatusCode: http.StatusNotFound, Status: "404 Not Found (offline snapshot)"}
+}
+
+// The Get methods below answer the same questions as their trello.Client
+// counterparts from the snapshot.
+
+func (s *Snapshot) GetMemberID() (string, error) {
+	if s.MemberID == "" {
+		return "", fmt.Errorf("snapshot does not record the current member; run 'trello_cli sync' again")
+	}
+	return s.MemberID, nil
+}
+
+func (s *Snapshot) GetCards(boardID string) ([]trello.Card, error) {
+	if boardID != s.Board.ID {
+		return nil, notFound()
+	}
+	return s.Cards, nil
+}
+
+func (s *Snapshot) GetLists(boardID string) ([]trello.List, error) {
+	if boardID != s.Board.ID {
+		return nil, notFound()
+	}
+	return s.Lists, nil
+}
+
+func (s *Snapshot) GetBoardMembers(boardID string) ([]trello.Member, error) {
+	if boardID != s.Board.ID {
+		return nil, notFound()
+	}
+	return s.Members, nil
+}
+
+// GetCardDetails finds a card by ID or short link.
+func (s *Snapshot) GetCardDetails(cardID string) (*trello.DetailedCard, error) {
+	for _, card := range s.Cards {
+		if card.ID == cardID || card.ShortLink == cardID {
+			return s.detail(card), nil
+		}
+	}
+	return nil, notFound()
+}
+
+func (s *Snapshot) GetBoardCard(boardID string, idShort int) (*trello.DetailedCard, error) {
+	if boardID == s.Board.ID {
+		for _, card := range s.Cards {
+			if card.IDShort == idShort {
+				return s.detail(card), nil
+			}
+		}
+	}
+	return nil, notFound()
+}
+
+// detail assembles the nested resources the API would include with a card.
+func (s *Snapshot) detail(card trello.Card) *trello.DetailedCard {
+	detailed := &trello.DetailedCard{
+		ID:        card.ID,
+		Name:      card.Name,
+		Desc:      card.Desc,
+		IDMembers: card.IDMembers,
+		ShortLink: card.ShortLink,
+		IDShort:   card.IDShort,
+		IDList:    card.IDList,
+		IDBoard:   s.Board.ID,
+		Closed:    card.Closed,
+		Labels:    card.Labels,
+	}
+
+	for _, member := range s.Members {
+		for _, id := range card.IDMembers {
+			if member.ID == id {
+				detailed.Members = append(detailed.Members, member)
+			}
+		}
+	}
+	for i, list := range s.Lists {
+		if list.ID == card.IDList {
+			detailed.List = &s.Lists[i]
+		}
+	}
+	for _, comment := range s.Comments {
+		if comment.Data.Card.ID == card.ID {
+			detailed.Comments = append(detailed.Comments, comment)
+		}
+	}
+	for _, checklist := range s.Checklists {
+		if checklist.IDCard == card.ID {
+			detailed.Checklists = append(detailed.Checklists, checklist)
+		}
+	}
+	return detailed
+}
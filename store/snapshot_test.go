@@ -0,0 +1,121 @@
+package store
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"trello_cli/trello"
+)
+
+func testSnapshot() *Snapshot {
+	var comment trello.Comment
+	comment.Data.Text = "looks good"
+	comment.Data.Card.ID = "C1"
+
+	return &Snapshot{
+		SyncedAt: time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC),
+		Board:    trello.Board{ID: "B1", Name: "Roadmap"},
+		MemberID: "M1",
+		Lists:    []trello.List{{ID: "L1", Name: "Todo"}, {ID: "L2", Name: "Done"}},
+		Members:  []trello.Member{{ID: "M1", Username: "ann"}, {ID: "M2", Username: "bo"}},
+		Cards: []trello.Card{
+			{ID: "C1", IDShort: 1, ShortLink: "abc", Name: "Write docs", IDList: "L2", IDMembers: []string{"M2"}},
+			{ID: "C2", IDShort: 2, ShortLink: "def", Name: "Ship", IDList: "L1"},
+		},
+		Checklists: []trello.Checklist{{ID: "K1", IDCard: "C1", Name: "Steps"}},
+		Comments:   []trello.Comment{comment},
+	}
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	useTempData(t)
+
+	if _, err := LoadSnapshot("B1"); !errors.Is(err, ErrNoSnapshot) {
+		t.Fatalf("LoadSnapshot before sync = %v, want ErrNoSnapshot", err)
+	}
+
+	if err := SaveSnapshot(testSnapshot()); err != nil {
+		t.Fatal(err)
+	}
+	snap, err := LoadSnapshot("B1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snap.Version != SnapshotVersion || !snap.SyncedAt.Equal(testSnapshot().SyncedAt) || len(snap.Cards) != 2 {
+		t.Errorf("loaded %+v, want the saved snapshot", snap)
+	}
+}
+
+func TestSnapshotFromOtherVersion(t *testing.T) {
+	useTempData(t)
+
+	path, err := SnapshotPath("B1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(`{"version":0,"board":{"id":"B1"}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadSnapshot("B1"); err == nil || !strings.Contains(err.Error(), "sync") {
+		t.Errorf("LoadSnapshot = %v, want a request to sync again", err)
+	}
+}
+
+func TestSnapshotCardDetails(t *testing.T) {
+	snap := testSnapshot()
+
+	for _, ref := range []string{"C1", "abc"} {
+		card, err := snap.GetCardDetails(ref)
+		if err != nil {
+			t.Fatalf("GetCardDetails(%q): %v", ref, err)
+		}
+		if card.IDShort != 1 || card.IDBoard != "B1" {
+			t.Errorf("GetCardDetails(%q) = #%d on %s, want #1 on B1", ref, card.IDShort, card.IDBoard)
+		}
+		if card.List == nil || card.List.Name != "Done" {
+			t.Errorf("card list = %+v, want Done", card.List)
+		}
+		if len(card.Members) != 1 || card.Members[0].Username != "bo" {
+			t.Errorf("card members = %+v, want bo", card.Members)
+		}
+		if len(card.Comments) != 1 || len(card.Checklists) != 1 {
+			t.Errorf("card has %d comments and %d checklists, want 1 each", len(card.Comments), len(card.Checklists))
+		}
+	}
+
+	card, err := snap.GetBoardCard("B1", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if card.ID != "C2" || len(card.Members) != 0 || len(card.Comments) != 0 {
+		t.Errorf("GetBoardCard(B1, 2) = %+v, want C2 with no members or comments", card)
+	}
+}
+
+func TestSnapshotNotFound(t *testing.T) {
+	snap := testSnapshot()
+
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{"unknown card", func() error { _, err := snap.GetCardDetails("zzz"); return err }},
+		{"unknown number", func() error { _, err := snap.GetBoardCard("B1", 99); return err }},
+		{"card on another board", func() error { _, err := snap.GetBoardCard("B2", 1); return err }},
+		{"cards of another board", func() error { _, err := snap.GetCards("B2"); return err }},
+		{"lists of another board", func() error { _, err := snap.GetLists("B2"); return err }},
+		{"members of another board", func() error { _, err := snap.GetBoardMembers("B2"); return err }},
+	}
+
+	for _, tt := range tests {
+		if err := tt.call(); !trello.IsNotFound(err) {
+			t.Errorf("%s: got %v, want a not-found error", tt.name, err)
+		}
+	}
+}
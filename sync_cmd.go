@@ -0,0 +1,82 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+	"trello_cli/store"
+)
+
+func runSyncCommand(args []string) {
+	fs := flag.NewFlagSet("sync", flag.ExitOnError)
+	fs.Parse(args)
+
+	// Always fetch fresh data; a snapshot of cached responses would hide its real age
+	clientFlags.noCache = true
+	cfg, client := mustLoadClient()
+
+	// Send changes made offline first so the snapshot includes them
+	if pending := replayQueue(client); pending > 0 {
+		fmt.Fprintf(os.Stderr, "Syncing anyway; queued changes will not show in the snapshot until they are sent.\n")
+	}
+
+	snap := &store.Snapshot{SyncedAt: time.Now()}
+	steps := []struct {
+		what  string
+		fetch func() error
+	}{
+		{"board", func() (err error) {
+			board, err := client.GetBoard(cfg.BoardID)
+			if err == nil {
+				snap.Board = *board
+				snap.Board.ID = cfg.BoardID
+			}
+			return err
+		}},
+		{"member", func() (err error) {
+			snap.MemberID, err = client.GetMemberID()
+			return err
+		}},
+		{"lists", func() (err error) {
+			snap.Lists, err = client.GetLists(cfg.BoardID)
+			return err
+		}},
+		{"labels", func() (err error) {
+			snap.Labels, err = client.GetLabels(cfg.BoardID)
+			return err
+		}},
+		{"members", func() (err error) {
+			snap.Members, err = client.GetBoardMembers(cfg.BoardID)
+			return err
+		}},
+		{"cards", func() (err error) {
+			snap.Cards, err = client.GetCards(cfg.BoardID)
+			return err
+		}},
+		{"checklists", func() (err error) {
+			snap.Checklists, err = client.GetBoardChecklists(cfg.BoardID)
+			return err
+		}},
+		{"comments", func() (err error) {
+			snap.Comments, err = client.GetBoardComments(cfg.BoardID)
+			return err
+		}},
+	}
+
+	for _, step := range steps {
+		if err := step.fetch(); err != nil {
+			log.Fatalf("Failed to sync %s: %v", step.what, err)
+		}
+	}
+
+	if err := store.SaveSnapshot(snap); err != nil {
+		log.Fatal(err)
+	}
+
+	path, _ := store.SnapshotPath(cfg.BoardID)
+	fmt.Printf("Synced %s: %d lists, %d cards, %d checklists, %d comments.\n",
+		snap.Board.Name, len(snap.Lists), len(snap.Cards), len(snap.Checklists), len(snap.Comments))
+	fmt.Printf("Snapshot saved to %s\n", path)
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"golang.org/x/term"
+)
+
+// tableColumn describes how a column of a table is laid out. Widths are in
+// terminal cells, so wide characters such as CJK and emoji count double.
+type tableColumn struct {
+	maxWidth int  // cap on the column's width; 0 for no cap
+	minWidth int  // narrowest the column shrinks to on a narrow terminal; 0 never shrinks
+	wrap     bool // wrap long values onto extra lines instead of ellipsizing them
+}
+
+// tableCell is one value of a table row with its style.
+type tableCell struct {
+	text  string
+	style lipgloss.Style
+}
+
+// tableGroup is a run of rows, optionally under a header line.
+type tableGroup struct {
+	header string // printed as is above the rows; empty for none
+	rows   [][]tableCell
+}
+
+// table lays out rows in aligned columns. Columns line up across groups.
+type table struct {
+	columns []tableColumn
+	groups  []tableGroup
+}
+
+// tableGap separates adjacent columns.
+const tableGap = " "
+
+var cellCleaner = strings.NewReplacer("\t", " ", "\r\n", " ", "\n", " ", "\r", " ")
+
+// terminalWidth returns the width of the terminal stdout is connected to, or
+// 0 when stdout is not a terminal.
+func terminalWidth() int {
+	fd := int(os.Stdout.Fd())
+	if !term.IsTerminal(fd) {
+		return 0
+	}
+	width, _, err := term.GetSize(fd)
+	if err != nil {
+		return 0
+	}
+	return width
+}
+
+// render writes the table to w, fitting it into width cells when width is
+// positive. With no width, as when output is piped, columns only honor their
+// caps and long values are never wrapped.
+func (t *table) render(w io.Writer, width int) {
+	for g := range t.groups {
+		for _, row := range t.groups[g].rows {
+			for c := range row {
+				row[c].text = cellCleaner.Replace(row[c].text)
+			}
+		}
+	}
+
+	widths := t.fit(width)
+	for g, group := range t.groups {
+		if group.header != "" {
+			if g > 0 {
+				fmt.Fprintln(w)
+			}
+			fmt.Fprintln(w, group.header)
+		}
+		for _, row := range group.rows {
+			t.renderRow(w, row, widths, width > 0)
+		}
+	}
+}
+
+// fit picks column widths: each column's widest value up to its cap, then,
+// if the table is wider than width, the widest shrinkable columns give up
+// cells until it fits or every column is at its minimum.
+func (t *table) fit(width int) []int {
+	widths := make([]int, len(t.columns))
+	for _, group := range t.groups {
+		for _, row := range group.rows {
+			for c, cell := range row {
+				if w := runewidth.StringWidth(cell.text); w > widths[c] {
+					widths[c] = w
+				}
+			}
+		}
+	}
+	for c, column := range t.columns {
+		if column.maxWidth > 0 && widths[c] > column.maxWidth {
+			widths[c] = column.maxWidth
+		}
+	}
+	if width <= 0 {
+		return widths
+	}
+
+	total := runewidth.StringWidth(tableGap) * (len(widths) - 1)
+	for _, w := range widths {
+		total += w
+	}
+	for total > width {
+		widest := -1
+		for c, column := range t.columns {
+			if column.minWidth == 0 || widths[c] <= column.minWidth {
+				continue
+			}
+			if widest < 0 || widths[c] > widths[widest] {
+				widest = c
+			}
+		}
+		if widest < 0 {
+			break
+		}
+		widths[widest]--
+		total--
+	}
+	return widths
+}
+
+// renderRow writes one row, which takes several lines when a wrapping column
+// has a long value.
+func (t *table) renderRow(w io.Writer, row []tableCell, widths []int, wrap bool) {
+	lines := make([][]string, len(row))
+	height := 1
+	for c, cell := range row {
+		if t.columns[c].wrap && wrap {
+			lines[c] = wrapText(cell.text, widths[c])
+		} else {
+			lines[c] = []string{runewidth.Truncate(cell.text, widths[c], "…")}
+		}
+		height = max(height, len(lines[c]))
+	}
+
+	for i := 0; i < height; i++ {
+		// Stop padding after the last value so lines have no trailing spaces
+		last := -1
+		for c := range row {
+			if i < len(lines[c]) && lines[c][i] != "" {
+				last = c
+			}
+		}
+
+		var b strings.Builder
+		for c := 0; c <= last; c++ {
+			text := ""
+			if i < len(lines[c]) {
+				text = lines[c][i]
+			}
+			if c < last {
+				// Apply styles after padding to keep the columns aligned
+				text = runewidth.FillRight(text, widths[c])
+			}
+			b.WriteString(row[c].style.Render(text))
+			if c < last {
+				b.WriteString(tableGap)
+			}
+		}
+		fmt.Fprintln(w, b.String())
+	}
+}
+
+// wrapText breaks text into lines of at most width cells, at spaces where
+// possible.
+func wrapText(text string, width int) []string {
+	if width <= 0 || runewidth.StringWidth(text) <= width {
+		return []string{text}
+	}
+
+	var lines []string
+	var line strings.Builder
+	lineWidth := 0
+	for _, word := range strings.Fields(text) {
+		wordWidth := runewidth.StringWidth(word)
+		if lineWidth > 0 && lineWidth+1+wordWidth <= width {
+			line.WriteString(" ")
+			line.WriteString(word)
+			lineWidth += 1 + wordWidth
+			continue
+		}
+		if lineWidth > 0 {
+			lines = append(lines, line.String())
+			line.Reset()
+			lineWidth = 0
+		}
+
+		// Break words that don't fit on a line of their own
+		for wordWidth > width {
+			head := runewidth.Truncate(word, width, "")
+			if head == "" {
+				// A single character wider than the column
+				head = string([]rune(word)[:1])
+			}
+			lines = append(lines, head)
+			word = word[len(head):]
+			wordWidth = runewidth.StringWidth(word)
+		}
+		line.WriteString(word)
+		lineWidth = wordWidth
+	}
+	if lineWidth > 0 {
+		lines = append(lines, line.String())
+	}
+	return lines
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestWrapText(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		width int
+		want  []string
+	}{
+		{"fits", "short", 10, []string{"short"}},
+		{"no width", "never wrapped at all", 0, []string{"never wrapped at all"}},
+		{"at spaces", "the quick brown fox", 10, []string{"the quick", "brown fox"}},
+		{"runs of spaces", "the   quick  brown", 10, []string{"the quick", "brown"}},
+		{"long word", "abcdefghij", 4, []string{"abcd", "efgh", "ij"}},
+		{"rest of a broken word starts the line", "abcdefghij k", 4, []string{"abcd", "efgh", "ij k"}},
+		{"wide runes", "日本語テキスト", 5, []string{"日本", "語テ", "キス", "ト"}},
+		{"wide rune after narrow ones", "ab日本", 3, []string{"ab", "日", "本"}},
+		{"rune wider than the column", "日本", 1, []string{"日", "本"}},
+		{"accented", "café crème brûlée", 10, []string{"café crème", "brûlée"}},
+		{"combining marks stay with their letter", "e\u0301e\u0301e\u0301", 2, []string{"e\u0301e\u0301", "e\u0301"}},
+		{"emoji modifiers stay with their emoji", "👍🏽👍🏽", 2, []string{"👍🏽", "👍🏽"}},
+		{"emoji", "🙂🙂🙂 ok", 4, []string{"🙂🙂", "🙂", "ok"}},
+		{"emoji and a short word", "🙂🙂🙂 k", 4, []string{"🙂🙂", "🙂 k"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := wrapText(tt.text, tt.width); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("wrapText(%q, %d) = %q, want %q", tt.text, tt.width, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTableFit(t *testing.T) {
+	cells := func(texts ...string) []tableCell {
+		row := make([]tableCell, len(texts))
+		for i, text := range texts {
+			row[i] = tableCell{text: text}
+		}
+		return row
+	}
+	rows := [][]tableCell{
+		cells("#1", "Fix the login page on Safari", "Doing"),
+		cells("#200", "Short", "In Progress"),
+	}
+
+	tests := []struct {
+		name    string
+		columns []tableColumn
+		width   int
+		want    []int
+	}{
+		{"widest values", []tableColumn{{}, {}, {}}, 0, []int{4, 28, 11}},
+		{"caps", []tableColumn{{}, {maxWidth: 20}, {maxWidth: 8}}, 0, []int{4, 20, 8}},
+		{"already fits", []tableColumn{{}, {minWidth: 10}, {}}, 80, []int{4, 28, 11}},
+		{"shrinks the shrinkable column", []tableColumn{{}, {minWidth: 10}, {}}, 30, []int{4, 13, 11}},
+		{"stops at the minimum", []tableColumn{{}, {minWidth: 10}, {}}, 20, []int{4, 10, 11}},
+		{"shrinks the widest first", []tableColumn{{}, {minWidth: 5}, {minWidth: 5}}, 24, []int{4, 9, 9}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := &table{columns: tt.columns, groups: []tableGroup{{rows: rows}}}
+			if got := table.fit(tt.width); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("fit(%d) = %v, want %v", tt.width, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTableFitWideRunes(t *testing.T) {
+	table := &table{
+		columns: []tableColumn{{}, {}},
+		groups:  []tableGroup{{rows: [][]tableCell{{{text: "日本語"}, {text: "🙂"}}}}},
+	}
+	if got, want := table.fit(0), []int{6, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("fit(0) = %v, want %v", got, want)
+	}
+}
+
+func TestTableRender(t *testing.T) {
+	tests := []struct {
+		name    string
+		columns []tableColumn
+		rows    [][]string
+		width   int
+		want    string
+	}{
+		{
+			name:    "aligned without trailing spaces",
+			columns: []tableColumn{{}, {}, {}},
+			rows:    [][]string{{"#1", "Fix login", "Doing"}, {"#20", "Docs", ""}},
+			want:    "#1  Fix login Doing\n#20 Docs\n",
+		},
+		{
+			name:    "ellipsized to the cap",
+			columns: []tableColumn{{}, {maxWidth: 6}},
+			rows:    [][]string{{"#1", "Fix the login page"}},
+			want:    "#1 Fix t…\n",
+		},
+		{
+			name:    "wrapped onto extra lines",
+			columns: []tableColumn{{}, {minWidth: 5, wrap: true}, {}},
+			rows:    [][]string{{"#1", "Fix the login page", "Doing"}},
+			width:   18,
+			want:    "#1 Fix the   Doing\n   login\n   page\n",
+		},
+		{
+			name:    "not wrapped when piped",
+			columns: []tableColumn{{}, {maxWidth: 10, wrap: true}},
+			rows:    [][]string{{"#1", "Fix the login page"}},
+			want:    "#1 Fix the l…\n",
+		},
+		{
+			name:    "newlines and tabs flattened",
+			columns: []tableColumn{{}, {}},
+			rows:    [][]string{{"#1", "two\nlines\tand tab"}},
+			want:    "#1 two lines and tab\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rows [][]tableCell
+			for _, texts := range tt.rows {
+				row := make([]tableCell, len(texts))
+				for i, text := range texts {
+					row[i] = tableCell{text: text, style: plainStyle}
+				}
+				rows = append(rows, row)
+			}
+			table := &table{columns: tt.columns, groups: []tableGroup{{rows: rows}}}
+
+			var buf bytes.Buffer
+			table.render(&buf, tt.width)
+			if got := buf.String(); got != tt.want {
+				t.Errorf("render(%d) =\n%q\nwant\n%q", tt.width, got, tt.want)
+			}
+		})
+	}
+}
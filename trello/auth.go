@@ -0,0 +1,208 @@
+package trello
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const DefaultAuthorizeURL = "https://trello.com/1/authorize"
+
+type AuthRequest struct {
+	APIKey     string
+	AppName    string
+	Scope      string // comma-separated, e.g. "read,write"
+	Expiration string // "1hour", "1day", "30days" or "never"
+
+	// AuthorizeURL overrides DefaultAuthorizeURL, e.g. to point at a local
+	// stand-in endpoint when testing.
+	AuthorizeURL string
+}
+
+// AuthSession is an in-progress authorization. Send the user to URL, then
+// call Wait for the token Trello hands back to the loopback callback.
+type AuthSession struct {
+	URL string
+
+	server *http.Server
+	state  string
+	tokens chan string
+	errs   chan error
+}
+
+var tokenPattern = regexp.MustCompile(`^[0-9A-Za-z]+$`)
+
+// StartAuthorization starts a callback server on the loopback interface and
+// builds the authorize URL that redirects back to it.
+func StartAuthorization(req AuthRequest) (*AuthSession, error) {
+	if req.APIKey == "" {
+		return nil, fmt.Errorf("an API key is required to authorize")
+	}
+
+	authorizeURL := req.AuthorizeURL
+	if authorizeURL == "" {
+		authorizeURL = DefaultAuthorizeURL
+	}
+
+	stateBytes := make([]byte, 16)
+	if _, err := rand.Read(stateBytes); err != nil {
+		return nil, err
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to start callback server: %w", err)
+	}
+
+	s := &AuthSession{
+		state:  hex.EncodeToString(stateBytes),
+		tokens: make(chan string, 1),
+		errs:   make(chan error, 1),
+	}
+
+	returnURL := fmt.Sprintf("http://%s/callback?state=%s", listener.Addr().String(), s.state)
+
+	u, err := url.Parse(authorizeURL)
+	if err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("invalid authorize URL: %w", err)
+	}
+	q := u.Query()
+	q.Set("key", req.APIKey)
+	q.Set("name", req.AppName)
+	q.Set("scope", req.Scope)
+	q.Set("expiration", req.Expiration)
+	q.Set("response_type", "token")
+	q.Set("callback_method", "fragment")
+	q.Set("return_url", returnURL)
+	u.RawQuery = q.Encode()
+	s.URL = u.String()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/callback", s.handleCallback)
+	mux.HandleFunc("/token", s.handleToken)
+	s.server = &http.Server{Handler: mux}
+
+	go func() {
+		if err := s.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			s.fail(err)
+		}
+	}()
+
+	return s, nil
+}
+
+// Wait blocks until a token arrives, the user denies access, or ctx ends.
+// The callback server is shut down before Wait returns.
+func (s *AuthSession) Wait(ctx context.Context) (string, error) {
+	defer s.Close()
+
+	select {
+	case token := <-s.tokens:
+		return token, nil
+	case err := <-s.errs:
+		return "", err
+	case <-ctx.Done():
+		return "", fmt.Errorf("authorization not completed: %w", ctx.Err())
+	}
+}
+
+func (s *AuthSession) Close() error {
+	return s.server.Close()
+}
+
+func (s *AuthSession) deliver(token string) {
+	select {
+	case s.tokens <- token:
+	default:
+	}
+}
+
+func (s *AuthSession) fail(err error) {
+	select {
+	case s.errs <- err:
+	default:
+	}
+}
+
+// handleCallback receives the browser after authorization. Trello puts the
+// token in the URL fragment, which never reaches the server, so the page
+// posts it back to /token. A token in the query string is accepted directly.
+func (s *AuthSession) handleCallback(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("state") != s.state {
+		http.Error(w, "state mismatch", http.StatusBadRequest)
+		return
+	}
+
+	if token := r.URL.Query().Get("token"); token != "" {
+		s.acceptToken(w, token)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintf(w, callbackPage, html.EscapeString(s.state))
+}
+
+func (s *AuthSession) handleToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	if r.PostForm.Get("state") != s.state {
+		http.Error(w, "state mismatch", http.StatusBadRequest)
+		return
+	}
+
+	if errText := r.PostForm.Get("error"); errText != "" {
+		s.fail(fmt.Errorf("authorization denied: %s", errText))
+		fmt.Fprint(w, "Authorization was denied. You can close this window.")
+		return
+	}
+
+	s.acceptToken(w, r.PostForm.Get("token"))
+}
+
+func (s *AuthSession) acceptToken(w http.ResponseWriter, token string) {
+	token = strings.TrimSpace(token)
+	if !tokenPattern.MatchString(token) {
+		http.Error(w, "missing or malformed token", http.StatusBadRequest)
+		return
+	}
+
+	s.deliver(token)
+	fmt.Fprint(w, "trello_cli is authorized. You can close this window.")
+}
+
+const callbackPage = `<!DOCTYPE html>
+<html>
+<head><title>trello_cli</title></head>
+<body>
+<p id="status">Completing authorization...</p>
+<script>
+(function () {
+  var params = new URLSearchParams(window.location.hash.replace(/^#/, ""));
+  var body = new URLSearchParams({ state: "%s" });
+  if (params.get("token")) {
+    body.set("token", params.get("token"));
+  } else {
+    body.set("error", params.get("error") || "no token returned");
+  }
+  fetch("/token", { method: "POST", body: body })
+    .then(function (r) { return r.text(); })
+    .then(function (t) { document.getElementById("status").textContent = t; });
+})();
+</script>
+</body>
+</html>
+`
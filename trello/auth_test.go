@@ -0,0 +1,179 @@
+package trello
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeAuthorizeServer stands in for Trello's authorize page: it checks the
+// request and redirects to return_url with fragment appended, as Trello does
+// once the user allows access.
+func fakeAuthorizeServer(t *testing.T, fragment string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/1/authorize" {
+			t.Errorf("authorize path = %q, want /1/authorize", r.URL.Path)
+		}
+		for key, want := range map[string]string{
+			"key":             "test-key",
+			"scope":           "read,write",
+			"expiration":      "30days",
+			"response_type":   "token",
+			"callback_method": "fragment",
+		} {
+			if got := q.Get(key); got != want {
+				t.Errorf("authorize %s = %q, want %q", key, got, want)
+			}
+		}
+		http.Redirect(w, r, q.Get("return_url")+"#"+fragment, http.StatusFound)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// followAuthorize plays the browser up to the callback page: it visits the
+// authorize URL and returns the redirect target, fragment included.
+func followAuthorize(t *testing.T, session *AuthSession) *url.URL {
+	t.Helper()
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get(session.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("authorize returned %s, want a redirect", resp.Status)
+	}
+	callback, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if callback.Hostname() != "127.0.0.1" {
+		t.Fatalf("callback host = %q, want the loopback interface", callback.Hostname())
+	}
+	return callback
+}
+
+func startTestAuthorization(t *testing.T, fragment string) *AuthSession {
+	t.Helper()
+	server := fakeAuthorizeServer(t, fragment)
+	session, err := StartAuthorization(AuthRequest{
+		APIKey:       "test-key",
+		AppName:      "trello_cli",
+		Scope:        "read,write",
+		Expiration:   "30days",
+		AuthorizeURL: server.URL + "/1/authorize",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { session.Close() })
+	return session
+}
+
+func get(t *testing.T, u string) (int, string) {
+	t.Helper()
+	resp, err := http.Get(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(body)
+}
+
+func postToken(t *testing.T, callback *url.URL, form url.Values) int {
+	t.Helper()
+	tokenURL := url.URL{Scheme: callback.Scheme, Host: callback.Host, Path: "/token"}
+	resp, err := http.PostForm(tokenURL.String(), form)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func waitBriefly(session *AuthSession) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	return session.Wait(ctx)
+}
+
+func TestAuthorizationFragmentCallback(t *testing.T) {
+	session := startTestAuthorization(t, "token=abc123")
+	callback := followAuthorize(t, session)
+
+	// The browser loads the callback page without the fragment...
+	state := callback.Query().Get("state")
+	status, page := get(t, callback.String())
+	if status != http.StatusOK {
+		t.Fatalf("callback returned %d, want 200", status)
+	}
+	if !strings.Contains(page, `state: "`+state+`"`) {
+		t.Fatalf("callback page does not post the state back")
+	}
+
+	// ...and its script posts the fragment's token to /token
+	fragment, err := url.ParseQuery(callback.Fragment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	form := url.Values{"state": {state}, "token": {fragment.Get("token")}}
+	if status := postToken(t, callback, form); status != http.StatusOK {
+		t.Fatalf("/token returned %d, want 200", status)
+	}
+
+	token, err := waitBriefly(session)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "abc123" {
+		t.Errorf("token = %q, want %q", token, "abc123")
+	}
+}
+
+func TestAuthorizationDenied(t *testing.T) {
+	session := startTestAuthorization(t, "error=access_denied")
+	callback := followAuthorize(t, session)
+
+	form := url.Values{"state": {callback.Query().Get("state")}, "error": {"access_denied"}}
+	if status := postToken(t, callback, form); status != http.StatusOK {
+		t.Fatalf("/token returned %d, want 200", status)
+	}
+
+	if _, err := waitBriefly(session); err == nil || !strings.Contains(err.Error(), "access_denied") {
+		t.Errorf("Wait returned %v, want the denial", err)
+	}
+}
+
+func TestAuthorizationStateMismatch(t *testing.T) {
+	session := startTestAuthorization(t, "token=abc123")
+	callback := followAuthorize(t, session)
+
+	forged := *callback
+	forged.RawQuery = url.Values{"state": {"forged"}, "token": {"evil"}}.Encode()
+	if status, _ := get(t, forged.String()); status != http.StatusBadRequest {
+		t.Errorf("callback with a forged state returned %d, want 400", status)
+	}
+
+	form := url.Values{"state": {"forged"}, "token": {"evil"}}
+	if status := postToken(t, callback, form); status != http.StatusBadRequest {
+		t.Errorf("/token with a forged state returned %d, want 400", status)
+	}
+
+	// Neither request may complete the login
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if token, err := session.Wait(ctx); err == nil {
+		t.Errorf("Wait returned token %q after a state mismatch", token)
+	}
+}
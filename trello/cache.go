@@ -0,0 +1,241 @@
+package trello
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// cacheTTLs lists how long responses stay fresh per endpoint. Endpoints not
+// listed are still stored but revalidated on every use, so an unchanged
+// response costs a 304 instead of a full download.
+var cacheTTLs = []struct {
+	pattern *regexp.Regexp
+	ttl     time.Duration
+}{
+	{regexp.MustCompile(`^/members/me$`), time.Hour},
+	{regexp.MustCompile(`^/members/me/organizations$`), time.Hour},
+	{regexp.MustCompile(`^/members/me/boards$`), time.Hour},
+	{regexp.MustCompile(`^/organizations/[^/]+/boards$`), time.Hour},
+	{regexp.MustCompile(`^/members/[^/]+$`), 24 * time.Hour},
+	{regexp.MustCompile(`^/boards/[^/]+/members$`), time.Hour},
+	{regexp.MustCompile(`^/boards/[^/]+/lists$`), 10 * time.Minute},
+	{regexp.MustCompile(`^/boards/[^/]+/labels$`), 10 * time.Minute},
+	{regexp.MustCompile(`^/boards/[^/]+$`), 10 * time.Minute},
+}
+
+func cacheTTL(endpoint string) time.Duration {
+	for _, entry := range cacheTTLs {
+		if entry.pattern.MatchString(endpoint) {
+			return entry.ttl
+		}
+	}
+	return 0
+}
+
+// DefaultCacheDir is where responses are cached unless configured otherwise.
+func DefaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot determine cache directory: %w", err)
+	}
+	return filepath.Join(dir, "trello_cli", "http"), nil
+}
+
+// ClearCache removes every cached response in dir.
+func ClearCache(dir string) error {
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("failed to clear cache: %w", err)
+	}
+	return nil
+}
+
+// EnableCache stores GET responses under dir. With refresh set, stored
+// responses are never served without revalidating them first.
+func (c *Client) EnableCache(dir string, refresh bool) {
+	base := c.client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	c.client.Transport = &cacheTransport{
+		dir:     dir,
+		base:    base,
+		refresh: refresh,
+	}
+}
+
+// Revalidate makes the client check cached responses with Trello before
+// using them from now on, as if the cache was enabled with refresh set.
+func (c *Client) Revalidate() {
+	if t, ok := c.client.Transport.(*cacheTransport); ok {
+		t.refresh = true
+	}
+}
+
+type cacheEntry struct {
+	StoredAt     time.Time `json:"stored_at"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	ContentType  string    `json:"content_type,omitempty"`
+	Body         []byte    `json:"body"`
+}
+
+type cacheTransport struct {
+	dir     string
+	base    http.RoundTripper
+	refresh bool
+}
+
+// cacheNamespace names the subdirectory holding one account's responses, so
+// accounts sharing a cache directory stay apart. It is derived from a hash of
+// the token, which never appears in clear form.
+func cacheNamespace(req *http.Request) string {
+	sum := sha256.Sum256([]byte(req.URL.Query().Get("token")))
+	return hex.EncodeToString(sum[:8])
+}
+
+// cacheKey identifies a request by method, path and parameters, leaving out
+// the API key and token.
+func cacheKey(req *http.Request) string {
+	q := req.URL.Query()
+	q.Del("key")
+	q.Del("token")
+
+	sum := sha256.Sum256([]byte(req.Method + " " + req.URL.Path + "?" + q.Encode()))
+	return hex.EncodeToString(sum[:])
+}
+
+func (t *cacheTransport) path(namespace, key string) string {
+	return filepath.Join(t.dir, namespace, key[:2], key+".json")
+}
+
+func (t *cacheTransport) load(namespace, key string) *cacheEntry {
+	data, err := os.ReadFile(t.path(namespace, key))
+	if err != nil {
+		return nil
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil
+	}
+	return &entry
+}
+
+// store writes the entry via a temp file so concurrent readers never see a
+// partial file. Failures only cost a future cache miss, so they are ignored.
+func (t *cacheTransport) store(namespace, key string, entry *cacheEntry) {
+	path := t.path(namespace, key)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".entry-*")
+	if err != nil {
+		return
+	}
+	_, werr := tmp.Write(data)
+	cerr := tmp.Close()
+	if werr != nil || cerr != nil || os.Rename(tmp.Name(), path) != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+func (t *cacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	namespace := cacheNamespace(req)
+	if req.Method != http.MethodGet {
+		resp, err := t.base.RoundTrip(req)
+		// Any successful write may change this account's cached reads, so
+		// start its namespace over; other accounts' entries are untouched
+		if err == nil && resp.StatusCode < 300 {
+			os.RemoveAll(filepath.Join(t.dir, namespace))
+		}
+		return resp, err
+	}
+
+	key := cacheKey(req)
+	endpoint := strings.TrimPrefix(req.URL.Path, "/1")
+	entry := t.load(namespace, key)
+
+	if entry != nil && !t.refresh && time.Since(entry.StoredAt) < cacheTTL(endpoint) {
+		return entry.response(req, "200 OK (cached)"), nil
+	}
+
+	if entry != nil {
+		req = req.Clone(req.Context())
+		if entry.ETag != "" {
+			req.Header.Set("If-None-Match", entry.ETag)
+		}
+		if entry.LastModified != "" {
+			req.Header.Set("If-Modified-Since", entry.LastModified)
+		}
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified && entry != nil {
+		resp.Body.Close()
+		entry.StoredAt = time.Now()
+		t.store(namespace, key, entry)
+		return entry.response(req, "200 OK (revalidated)"), nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return resp, nil
+	}
+
+	// Without a TTL or validators a stored copy could never be reused
+	etag, lastModified := resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+	if cacheTTL(endpoint) == 0 && etag == "" && lastModified == "" {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	t.store(namespace, key, &cacheEntry{
+		StoredAt:     time.Now(),
+		ETag:         etag,
+		LastModified: lastModified,
+		ContentType:  resp.Header.Get("Content-Type"),
+		Body:         body,
+	})
+
+	return resp, nil
+}
+
+func (e *cacheEntry) response(req *http.Request, status string) *http.Response {
+	header := http.Header{}
+	if e.ContentType != "" {
+		header.Set("Content-Type", e.ContentType)
+	}
+	return &http.Response{
+		Status:        status,
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
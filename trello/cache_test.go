@@ -0,0 +1,215 @@
+package trello
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeTrello counts requests per method and path and answers GETs with a
+// fixed body. Paths under /1/etag/ carry an ETag and honor If-None-Match.
+type fakeTrello struct {
+	mu     sync.Mutex
+	hits   map[string]int
+	notMod int
+	body   string
+	status int // for writes; 0 means 200
+}
+
+func (f *fakeTrello) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.hits[r.Method+" "+r.URL.Path]++
+
+	if r.Method != http.MethodGet {
+		if f.status != 0 {
+			w.WriteHeader(f.status)
+		}
+		return
+	}
+	if strings.HasPrefix(r.URL.Path, "/1/etag/") {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			f.notMod++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	io.WriteString(w, f.body)
+}
+
+func newCacheTest(t *testing.T) (*fakeTrello, *httptest.Server, *cacheTransport) {
+	t.Helper()
+	fake := &fakeTrello{hits: make(map[string]int), body: `[{"id":"L1"}]`}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return fake, server, &cacheTransport{dir: t.TempDir(), base: http.DefaultTransport}
+}
+
+func roundTrip(t *testing.T, transport *cacheTransport, method, url string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	return resp
+}
+
+func TestCacheRoundTrip(t *testing.T) {
+	type step struct {
+		method, path, token string
+	}
+	lists := func(token string) step { return step{"GET", "/1/boards/B1/lists", token} }
+
+	tests := []struct {
+		name        string
+		steps       []step
+		refresh     bool // set after the first step
+		writeStatus int
+		hits        map[string]int // requests the server should see
+		notModified int
+		lastStatus  string
+	}{
+		{
+			name:       "fresh entry served from disk",
+			steps:      []step{lists("t"), lists("t")},
+			hits:       map[string]int{"GET /1/boards/B1/lists": 1},
+			lastStatus: "200 OK (cached)",
+		},
+		{
+			name:        "stale entry revalidated with its ETag",
+			steps:       []step{{"GET", "/1/etag/cards", "t"}, {"GET", "/1/etag/cards", "t"}},
+			hits:        map[string]int{"GET /1/etag/cards": 2},
+			notModified: 1,
+			lastStatus:  "200 OK (revalidated)",
+		},
+		{
+			name:       "refresh revalidates fresh entries",
+			steps:      []step{lists("t"), lists("t")},
+			refresh:    true,
+			hits:       map[string]int{"GET /1/boards/B1/lists": 2},
+			lastStatus: "200 OK",
+		},
+		{
+			name:       "no TTL and no validators: not stored",
+			steps:      []step{{"GET", "/1/search", "t"}, {"GET", "/1/search", "t"}},
+			hits:       map[string]int{"GET /1/search": 2},
+			lastStatus: "200 OK",
+		},
+		{
+			name:  "write clears only its own account",
+			steps: []step{lists("alice"), lists("bob"), {"POST", "/1/cards", "alice"}, lists("alice"), lists("bob")},
+			hits:  map[string]int{"GET /1/boards/B1/lists": 3, "POST /1/cards": 1},
+			// bob's last read is his cached copy
+			lastStatus: "200 OK (cached)",
+		},
+		{
+			name:        "failed write keeps entries",
+			steps:       []step{lists("t"), {"PUT", "/1/cards/C1", "t"}, lists("t")},
+			writeStatus: http.StatusBadRequest,
+			hits:        map[string]int{"GET /1/boards/B1/lists": 1, "PUT /1/cards/C1": 1},
+			lastStatus:  "200 OK (cached)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, server, transport := newCacheTest(t)
+			fake.status = tt.writeStatus
+
+			var last *http.Response
+			for i, s := range tt.steps {
+				if i == 1 {
+					transport.refresh = tt.refresh
+				}
+				last = roundTrip(t, transport, s.method, server.URL+s.path+"?key=k&token="+s.token)
+			}
+
+			fake.mu.Lock()
+			hits, notModified := fake.hits, fake.notMod
+			fake.mu.Unlock()
+			if !reflect.DeepEqual(hits, tt.hits) {
+				t.Errorf("server saw %v, want %v", hits, tt.hits)
+			}
+			if notModified != tt.notModified {
+				t.Errorf("server answered %d requests with 304, want %d", notModified, tt.notModified)
+			}
+			if last.Status != tt.lastStatus {
+				t.Errorf("last status = %q, want %q", last.Status, tt.lastStatus)
+			}
+		})
+	}
+}
+
+func TestCacheRevalidatedBodyComesFromDisk(t *testing.T) {
+	fake, server, transport := newCacheTest(t)
+	url := server.URL + "/1/etag/cards?key=k&token=t"
+
+	roundTrip(t, transport, "GET", url)
+	fake.body = "changed, but the server says 304"
+
+	req, _ := http.NewRequest("GET", url, nil)
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != `[{"id":"L1"}]` {
+		t.Errorf("body = %q, want the cached one", body)
+	}
+	if got := resp.Header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q, want the cached one", got)
+	}
+}
+
+func TestCacheKeepsCredentialsOutOfTheCache(t *testing.T) {
+	_, server, transport := newCacheTest(t)
+	roundTrip(t, transport, "GET", server.URL+"/1/boards/B1/lists?key=secretkey&token=secrettoken")
+
+	err := filepath.Walk(transport.dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if strings.Contains(path, "secret") {
+			t.Errorf("cache path %s contains a credential", path)
+		}
+		if !info.IsDir() {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			if strings.Contains(string(data), "secret") {
+				t.Errorf("cache file %s contains a credential", path)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRevalidate(t *testing.T) {
+	client := NewClient("k", "t")
+	client.Revalidate() // no cache: nothing to do
+
+	client.EnableCache(t.TempDir(), false)
+	client.Revalidate()
+	if transport := client.client.Transport.(*cacheTransport); !transport.refresh {
+		t.Errorf("Revalidate did not set refresh")
+	}
+}
@@ -0,0 +1,146 @@
+package trello
+
+import "sync"
+
+// memberLookupWorkers bounds concurrent /members/{id} requests so large
+// cards don't trip Trello's rate limits.
+const memberLookupWorkers = 4
+
+// memberCache memoizes members for the lifetime of the client.
+type memberCache struct {
+	mu     sync.Mutex
+	byID   map[string]Member
+	boards map[string]bool // boards whose member list has been requested
+}
+
+func (c *Client) memberCache() *memberCache {
+	c.membersOnce.Do(func() {
+		c.members = &memberCache{
+			byID:   make(map[string]Member),
+			boards: make(map[string]bool),
+		}
+	})
+	return c.members
+}
+
+// PrimeMembers records members that arrived nested in another response, so
+// later lookups need no request.
+func (c *Client) PrimeMembers(members []Member) {
+	cache := c.memberCache()
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	for _, m := range members {
+		if m.ID != "" {
+			cache.byID[m.ID] = m
+		}
+	}
+}
+
+func (c *Client) missingMembers(ids []string) []string {
+	cache := c.memberCache()
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	seen := make(map[string]bool)
+	var missing []string
+	for _, id := range ids {
+		if _, ok := cache.byID[id]; !ok && !seen[id] {
+			seen[id] = true
+			missing = append(missing, id)
+		}
+	}
+	return missing
+}
+
+// ResolveMembers returns the members for ids, keyed by ID. Unknown IDs are
+// looked up with a single board members request when boardID is given, and
+// any left over are fetched individually with bounded concurrency. IDs that
+// cannot be resolved are absent from the result; the error reports the first
+// failed lookup.
+func (c *Client) ResolveMembers(boardID string, ids []string) (map[string]Member, error) {
+	cache := c.memberCache()
+	var firstErr error
+
+	missing := c.missingMembers(ids)
+	if len(missing) > 0 && boardID != "" {
+		cache.mu.Lock()
+		fetched := cache.boards[boardID]
+		cache.mu.Unlock()
+
+		if !fetched {
+			members, err := c.GetBoardMembers(boardID)
+			if err != nil {
+				firstErr = err
+			}
+			c.PrimeMembers(members)
+
+			// Remembered once it completes, failed or not: a failure is not
+			// retried for every card, and unresolved members are fetched
+			// one by one below instead
+			cache.mu.Lock()
+			cache.boards[boardID] = true
+			cache.mu.Unlock()
+			missing = c.missingMembers(ids)
+		}
+	}
+
+	if len(missing) > 0 {
+		if err := c.fetchMembers(missing); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	result := make(map[string]Member, len(ids))
+	for _, id := range ids {
+		if m, ok := cache.byID[id]; ok {
+			result[id] = m
+		}
+	}
+	return result, firstErr
+}
+
+// fetchMembers looks up members one by one using a small worker pool.
+func (c *Client) fetchMembers(ids []string) error {
+	jobs := make(chan string)
+	var (
+		wg       sync.WaitGroup
+		errMu    sync.Mutex
+		firstErr error
+	)
+
+	workers := memberLookupWorkers
+	if len(ids) < workers {
+		workers = len(ids)
+	}
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range jobs {
+				member, err := c.GetMember(id)
+				if err != nil {
+					errMu.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					errMu.Unlock()
+					continue
+				}
+				c.PrimeMembers([]Member{*member})
+			}
+		}()
+	}
+
+	for _, id := range ids {
+		jobs <- id
+	}
+	close(jobs)
+	wg.Wait()
+
+	return firstErr
+}
@@ -0,0 +1,21 @@
+package trello
+
+import (
+	"errors"
+	"net/http"
+)
+
+// ErrOffline is returned for every request made by a client in offline mode.
+var ErrOffline = errors.New("offline mode: Trello cannot be reached")
+
+type offlineTransport struct{}
+
+func (offlineTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, ErrOffline
+}
+
+// SetOffline makes every request fail immediately with ErrOffline, so code
+// working from local data never waits on the network.
+func (c *Client) SetOffline() {
+	c.client.Transport = offlineTransport{}
+}
@@ -2,10 +2,14 @@ package trello
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
+	"sync"
+	"time"
 )
 
 const baseURL = "https://api.trello.com/1"
@@ -14,31 +18,56 @@ type Client struct {
 	apiKey   string
 	apiToken string
 	client   *http.Client
+	trace    io.Writer
+
+	membersOnce sync.Once
+	members     *memberCache
 }
 
 type Card struct {
 	ID        string   `json:"id"`
 	Name      string   `json:"name"`
+	Desc      string   `json:"desc"`
 	IDMembers []string `json:"idMembers"`
 	ShortLink string   `json:"shortLink"`
 	IDShort   int      `json:"idShort"`
 	IDList    string   `json:"idList"`
+	IDBoard   string   `json:"idBoard"`
+	Closed    bool     `json:"closed"`
+	Due       string   `json:"due"` // RFC 3339, empty when unset
+	IDLabels  []string `json:"idLabels"`
+	Labels    []Label  `json:"labels"`
+
+	Pos              float64 `json:"pos"` // order within the list
+	DateLastActivity string  `json:"dateLastActivity"`
+	Badges           struct {
+		Comments int `json:"comments"`
+	} `json:"badges"`
 }
 
 type Board struct {
-	ID   string `json:"id"`
-	Name string `json:"name"`
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Closed bool   `json:"closed"`
+	URL    string `json:"url"`
 }
 
 type List struct {
-	ID   string `json:"id"`
-	Name string `json:"name"`
+	ID   string  `json:"id"`
+	Name string  `json:"name"`
+	Pos  float64 `json:"pos"` // order on the board
+
+	// SoftLimit is the list's WIP limit set in Trello, empty when unset
+	SoftLimit json.Number `json:"softLimit"`
 }
 
 type Comment struct {
 	ID   string `json:"id"`
 	Data struct {
 		Text string `json:"text"`
+		Card struct {
+			ID string `json:"id"`
+		} `json:"card"`
 	} `json:"data"`
 	Date          string `json:"date"`
 	MemberCreator struct {
@@ -55,11 +84,35 @@ type DetailedCard struct {
 	ShortLink        string   `json:"shortLink"`
 	IDShort          int      `json:"idShort"`
 	IDList           string   `json:"idList"`
+	IDBoard          string   `json:"idBoard"`
 	Closed           bool     `json:"closed"`
 	DateLastActivity string   `json:"dateLastActivity"`
-	Labels           []struct {
-		Name string `json:"name"`
-	} `json:"labels"`
+	Labels           []Label  `json:"labels"`
+
+	// Nested resources included by GetCardDetails and GetBoardCard
+	Members    []Member    `json:"members"`
+	List       *List       `json:"list"`
+	Comments   []Comment   `json:"actions"`
+	Checklists []Checklist `json:"checklists"`
+}
+
+type Checklist struct {
+	ID         string      `json:"id"`
+	Name       string      `json:"name"`
+	IDCard     string      `json:"idCard"`
+	CheckItems []CheckItem `json:"checkItems"`
+}
+
+type CheckItem struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	State string `json:"state"` // "complete" or "incomplete"
+}
+
+type Label struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Color string `json:"color"`
 }
 
 type Organization struct {
@@ -102,7 +155,74 @@ func (c *Client) makeRequest(method, endpoint string, params map[string]string)
 		return nil, err
 	}
 
-	return c.client.Do(req)
+	if c.trace == nil {
+		return c.client.Do(req)
+	}
+
+	start := time.Now()
+	resp, err := c.client.Do(req)
+	status := "error"
+	if err == nil {
+		status = resp.Status
+	}
+	fmt.Fprintf(c.trace, "trello: %s %s -> %s (%s)\n", method, endpoint, status, time.Since(start).Round(time.Millisecond))
+	return resp, err
+}
+
+// SetTrace logs every request with its status and duration to w. Credentials
+// are never written. Pass nil to disable.
+func (c *Client) SetTrace(w io.Writer) {
+	c.trace = w
+}
+
+// SetTimeout limits how long each request may take, including reading the
+// response. Zero means no limit, the default.
+func (c *Client) SetTimeout(d time.Duration) {
+	c.client.Timeout = d
+}
+
+// APIError is returned for non-200 responses.
+type APIError struct {
+	StatusCode int
+	Status     string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API request failed with status: %s", e.Status)
+}
+
+// IsNotFound reports whether err means the requested object doesn't exist.
+// Trello answers 400 for IDs it cannot parse and 404 for unknown ones.
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusNotFound || apiErr.StatusCode == http.StatusBadRequest
+	}
+	return false
+}
+
+// doJSON performs a request and decodes a successful JSON response into out.
+// A nil out discards the body.
+func (c *Client) doJSON(method, endpoint string, params map[string]string, out interface{}) error {
+	resp, err := c.makeRequest(method, endpoint, params)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return &APIError{StatusCode: resp.StatusCode, Status: resp.Status}
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(body, out)
 }
 
 func (c *Client) GetCards(boardID string) ([]Card, error) {
@@ -181,6 +301,14 @@ func (c *Client) GetMember(memberID string) (*Member, error) {
 	return &member, nil
 }
 
+func (c *Client) GetCurrentMember() (*Member, error) {
+	var member Member
+	if err := c.doJSON("GET", "/members/me", nil, &member); err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
 func (c *Client) GetOrganizations() ([]Organization, error) {
 	resp, err := c.makeRequest("GET", "/members/me/organizations", nil)
 	if err != nil {
@@ -231,6 +359,87 @@ func (c *Client) GetBoards(organizationID string) ([]Board, error) {
 	return boards, nil
 }
 
+// GetMemberBoards returns every board the current member belongs to, in any
+// workspace.
+func (c *Client) GetMemberBoards() ([]Board, error) {
+	var boards []Board
+	if err := c.doJSON("GET", "/members/me/boards", map[string]string{
+		"fields": "name,closed,url",
+	}, &boards); err != nil {
+		return nil, err
+	}
+	return boards, nil
+}
+
+// GetMemberCards returns the open cards the current member is assigned to,
+// across all boards.
+func (c *Client) GetMemberCards() ([]Card, error) {
+	var cards []Card
+	if err := c.doJSON("GET", "/members/me/cards", map[string]string{
+		"filter": "open",
+	}, &cards); err != nil {
+		return nil, err
+	}
+	return cards, nil
+}
+
+// SearchOptions scopes a card search. With no boards or organizations set,
+// every board the member can see is searched.
+type SearchOptions struct {
+	BoardIDs        []string
+	OrganizationIDs []string
+	Limit           int // at most 1000; Trello's default is 10
+}
+
+// SearchCard is a search result with its board and list nested.
+type SearchCard struct {
+	Card
+	Board *Board `json:"board"`
+	List  *List  `json:"list"`
+}
+
+// Search finds cards matching query using Trello's search syntax, which
+// covers names, descriptions and comments and understands operators such as
+// label:, @member, list:, is:open and due:week. Results are ordered by
+// relevance.
+func (c *Client) Search(query string, opts SearchOptions) ([]SearchCard, error) {
+	params := map[string]string{
+		"query":       query,
+		"modelTypes":  "cards",
+		"partial":     "true",
+		"card_board":  "true",
+		"card_list":   "true",
+		"card_fields": "name,desc,idShort,idList,idBoard,idMembers,idLabels,labels,shortLink,closed,due,pos,dateLastActivity,badges",
+	}
+	if len(opts.BoardIDs) > 0 {
+		params["idBoards"] = strings.Join(opts.BoardIDs, ",")
+	}
+	if len(opts.OrganizationIDs) > 0 {
+		params["idOrganizations"] = strings.Join(opts.OrganizationIDs, ",")
+	}
+	if opts.Limit > 0 {
+		params["cards_limit"] = fmt.Sprint(opts.Limit)
+	}
+
+	var result struct {
+		Cards []SearchCard `json:"cards"`
+	}
+	if err := c.doJSON("GET", "/search", params, &result); err != nil {
+		return nil, err
+	}
+	return result.Cards, nil
+}
+
+func (c *Client) GetBoard(boardID string) (*Board, error) {
+	var board Board
+	if err := c.doJSON("GET", fmt.Sprintf("/boards/%s", boardID), map[string]string{
+		"fields": "name,closed,url",
+	}, &board); err != nil {
+		return nil, err
+	}
+	return &board, nil
+}
+
 func (c *Client) GetLists(boardID string) ([]List, error) {
 	resp, err := c.makeRequest("GET", fmt.Sprintf("/boards/%s/lists", boardID), nil)
 	if err != nil {
@@ -255,55 +464,139 @@ func (c *Client) GetLists(boardID string) ([]List, error) {
 	return lists, nil
 }
 
-func (c *Client) GetCardDetails(cardID string) (*DetailedCard, error) {
-	resp, err := c.makeRequest("GET", fmt.Sprintf("/cards/%s", cardID), map[string]string{
-		"members": "true",
-		"labels":  "true",
-	})
-	if err != nil {
+func (c *Client) GetLabels(boardID string) ([]Label, error) {
+	var labels []Label
+	if err := c.doJSON("GET", fmt.Sprintf("/boards/%s/labels", boardID), nil, &labels); err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
+	return labels, nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API request failed with status: %s", resp.Status)
+func (c *Client) GetBoardMembers(boardID string) ([]Member, error) {
+	var members []Member
+	if err := c.doJSON("GET", fmt.Sprintf("/boards/%s/members", boardID), nil, &members); err != nil {
+		return nil, err
 	}
+	return members, nil
+}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
+// cardDetailParams nests the card's comments, members and list into the card
+// response so a single request has everything the detail view needs.
+var cardDetailParams = map[string]string{
+	"members":       "true",
+	"member_fields": "fullName,username",
+	"labels":        "true",
+	"list":          "true",
+	"actions":       "commentCard",
+	"checklists":    "all",
+}
+
+func (c *Client) GetCardDetails(cardID string) (*DetailedCard, error) {
+	var card DetailedCard
+	if err := c.doJSON("GET", fmt.Sprintf("/cards/%s", cardID), cardDetailParams, &card); err != nil {
 		return nil, err
 	}
+	return &card, nil
+}
 
+// GetBoardCard looks up a card by its board-local number (idShort).
+func (c *Client) GetBoardCard(boardID string, idShort int) (*DetailedCard, error) {
 	var card DetailedCard
-	if err := json.Unmarshal(body, &card); err != nil {
+	if err := c.doJSON("GET", fmt.Sprintf("/boards/%s/cards/%d", boardID, idShort), cardDetailParams, &card); err != nil {
 		return nil, err
 	}
-
 	return &card, nil
 }
 
-func (c *Client) GetCardComments(cardID string) ([]Comment, error) {
-	resp, err := c.makeRequest("GET", fmt.Sprintf("/cards/%s/actions", cardID), map[string]string{
-		"filter": "commentCard",
-	})
-	if err != nil {
+func (c *Client) GetBoardChecklists(boardID string) ([]Checklist, error) {
+	var checklists []Checklist
+	if err := c.doJSON("GET", fmt.Sprintf("/boards/%s/checklists", boardID), nil, &checklists); err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
+	return checklists, nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API request failed with status: %s", resp.Status)
+// boardActionsPageSize is the most actions Trello returns per request.
+const boardActionsPageSize = 1000
+
+// GetBoardComments returns every comment on the board's cards, newest
+// first, following Trello's pagination.
+func (c *Client) GetBoardComments(boardID string) ([]Comment, error) {
+	var comments []Comment
+	params := map[string]string{
+		"filter": "commentCard",
+		"limit":  fmt.Sprint(boardActionsPageSize),
+	}
+	for {
+		var page []Comment
+		if err := c.doJSON("GET", fmt.Sprintf("/boards/%s/actions", boardID), params, &page); err != nil {
+			return nil, err
+		}
+		comments = append(comments, page...)
+		if len(page) < boardActionsPageSize {
+			return comments, nil
+		}
+		params["before"] = page[len(page)-1].ID
 	}
+}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
+// CreateCard adds a card to a list, assigned to memberIDs if any are given.
+func (c *Client) CreateCard(listID, name, desc string, memberIDs []string) (*Card, error) {
+	params := map[string]string{
+		"idList": listID,
+		"name":   name,
+		"desc":   desc,
+	}
+	if len(memberIDs) > 0 {
+		params["idMembers"] = strings.Join(memberIDs, ",")
 	}
 
-	var comments []Comment
-	if err := json.Unmarshal(body, &comments); err != nil {
+	var card Card
+	if err := c.doJSON("POST", "/cards", params, &card); err != nil {
 		return nil, err
 	}
+	return &card, nil
+}
+
+func (c *Client) MoveCard(cardID, listID string) error {
+	return c.doJSON("PUT", fmt.Sprintf("/cards/%s", cardID), map[string]string{
+		"idList": listID,
+		"pos":    "top",
+	}, nil)
+}
+
+func (c *Client) ArchiveCard(cardID string) error {
+	return c.doJSON("PUT", fmt.Sprintf("/cards/%s", cardID), map[string]string{
+		"closed": "true",
+	}, nil)
+}
+
+func (c *Client) AddCardMember(cardID, memberID string) error {
+	return c.doJSON("POST", fmt.Sprintf("/cards/%s/idMembers", cardID), map[string]string{
+		"value": memberID,
+	}, nil)
+}
 
-	return comments, nil
+func (c *Client) RemoveCardMember(cardID, memberID string) error {
+	return c.doJSON("DELETE", fmt.Sprintf("/cards/%s/idMembers/%s", cardID, memberID), nil, nil)
+}
+
+func (c *Client) AddCardLabel(cardID, labelID string) error {
+	return c.doJSON("POST", fmt.Sprintf("/cards/%s/idLabels", cardID), map[string]string{
+		"value": labelID,
+	}, nil)
+}
+
+func (c *Client) RemoveCardLabel(cardID, labelID string) error {
+	return c.doJSON("DELETE", fmt.Sprintf("/cards/%s/idLabels/%s", cardID, labelID), nil, nil)
+}
+
+func (c *Client) AddComment(cardID, text string) (*Comment, error) {
+	var comment Comment
+	if err := c.doJSON("POST", fmt.Sprintf("/cards/%s/actions/comments", cardID), map[string]string{
+		"text": text,
+	}, &comment); err != nil {
+		return nil, err
+	}
+	return &comment, nil
 }
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"trello_cli/config"
+)
+
+const viewUsage = `Usage: trello_cli view <command>
+
+Commands:
+  <name> [flags]           Run a saved view; extra flags override the saved ones
+  save <name> <flags...>   Save listing flags as a view, replacing any existing one
+  list                     Show saved views
+  delete <name>            Delete a view
+
+Views take the same flags as the listing, e.g.
+  trello_cli view save standup -A -l Doing,Review -w 'label != blocked' -o tsv
+`
+
+func runViewCommand(args []string) {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, viewUsage)
+		os.Exit(2)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	switch args[0] {
+	case "save":
+		if len(args) < 3 {
+			fmt.Fprint(os.Stderr, viewUsage)
+			os.Exit(2)
+		}
+		saveView(cfg, args[1], args[2:])
+	case "list":
+		listViews(cfg)
+	case "delete":
+		if len(args) != 2 {
+			fmt.Fprint(os.Stderr, viewUsage)
+			os.Exit(2)
+		}
+		deleteView(cfg, args[1])
+	case "help", "-h", "--help":
+		fmt.Print(viewUsage)
+	default:
+		view, ok := cfg.Views[args[0]]
+		if !ok {
+			log.Fatalf("No view named %q; see 'trello_cli view list'", args[0])
+		}
+		// Later flags win, so extra flags override the saved ones
+		runListCommand(append(append([]string(nil), view.Args...), args[1:]...))
+	}
+}
+
+// reservedViewNames are the view subcommands, which would shadow a view.
+var reservedViewNames = map[string]bool{"save": true, "list": true, "delete": true, "help": true}
+
+func saveView(cfg *config.Config, name string, args []string) {
+	if reservedViewNames[name] || strings.HasPrefix(name, "-") || strings.TrimSpace(name) == "" {
+		log.Fatalf("%q cannot be used as a view name", name)
+	}
+
+	// Check the flags now rather than when the view is next run
+	fs, opts := newListFlags(flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	if err := fs.Parse(args); err != nil {
+		log.Fatalf("Invalid flags for view %s: %v", name, err)
+	}
+	if fs.NArg() > 0 {
+		log.Fatalf("Invalid flags for view %s: unexpected argument %q", name, fs.Arg(0))
+	}
+	if _, err := opts.validate(); err != nil {
+		log.Fatalf("Invalid flags for view %s: %v", name, err)
+	}
+
+	_, existed := cfg.Views[name]
+	if cfg.Views == nil {
+		cfg.Views = make(map[string]config.View)
+	}
+	cfg.Views[name] = config.View{Args: args}
+	if err := config.SaveConfig(cfg); err != nil {
+		log.Fatalf("Failed to save config: %v", err)
+	}
+
+	if existed {
+		fmt.Printf("Updated view %s\n", name)
+	} else {
+		fmt.Printf("Saved view %s; run it with 'trello_cli view %s'\n", name, name)
+	}
+}
+
+func listViews(cfg *config.Config) {
+	if len(cfg.Views) == 0 {
+		fmt.Println("No saved views. Create one with 'trello_cli view save <name> <flags...>'.")
+		return
+	}
+
+	names := make([]string, 0, len(cfg.Views))
+	width := 0
+	for name := range cfg.Views {
+		names = append(names, name)
+		if len(name) > width {
+			width = len(name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		args := cfg.Views[name].Args
+		quoted := make([]string, len(args))
+		for i, arg := range args {
+			quoted[i] = shellQuote(arg)
+		}
+		fmt.Printf("%-*s  %s\n", width, name, strings.Join(quoted, " "))
+	}
+}
+
+func deleteView(cfg *config.Config, name string) {
+	if _, ok := cfg.Views[name]; !ok {
+		log.Fatalf("No view named %q", name)
+	}
+	delete(cfg.Views, name)
+	if err := config.SaveConfig(cfg); err != nil {
+		log.Fatalf("Failed to save config: %v", err)
+	}
+	fmt.Printf("Deleted view %s\n", name)
+}
+
+// shellQuote quotes arg for display so it can be pasted back into a shell.
+func shellQuote(arg string) string {
+	if arg != "" && strings.Trim(arg, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_=,./:@") == "" {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
@@ -0,0 +1,320 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+	"trello_cli/config"
+	"trello_cli/store"
+	"trello_cli/trello"
+)
+
+// cardWriter sends changes to Trello, or queues them when working offline
+// or when Trello cannot be reached.
+type cardWriter struct {
+	cfg    *config.Config
+	client *trello.Client
+	reader boardReader
+	snap   *store.Snapshot // set in offline mode
+
+	// queueOnly is set when earlier queued changes are still pending, so
+	// new changes wait behind them to keep their order
+	queueOnly bool
+}
+
+func mustLoadWriter(offline bool) *cardWriter {
+	if offline {
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			log.Fatalf("Failed to load config: %v", err)
+		}
+		snap, client := mustLoadOffline(cfg)
+		return &cardWriter{cfg: cfg, client: client, reader: snap, snap: snap}
+	}
+
+	cfg, client := mustLoadClient()
+	w := &cardWriter{cfg: cfg, client: client, reader: client}
+
+	// Changes made offline go first so Trello sees everything in order
+	if pending := replayQueue(client); pending > 0 {
+		w.queueOnly = true
+	}
+	return w
+}
+
+// submit applies op, queuing it instead when offline or unreachable.
+func (w *cardWriter) submit(op store.Op) {
+	op.BoardID = w.cfg.BoardID
+
+	if w.snap == nil && !w.queueOnly {
+		result, err := applyOp(w.client, op)
+		if err == nil {
+			fmt.Println(result)
+			return
+		}
+		if !canQueue(op, err) {
+			log.Fatal(unknownOutcome(err))
+		}
+		fmt.Fprintf(os.Stderr, "Trello is unreachable (%v); queuing the change.\n", err)
+	}
+
+	if err := store.Enqueue(&op); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("Queued change %d: %s\n", op.Seq, op.Describe())
+	fmt.Println("It will be sent by 'trello_cli queue push' or 'trello_cli sync'.")
+
+	// Keep the offline view consistent with what was just done
+	if w.snap != nil {
+		applyToSnapshot(w.snap, op)
+		if err := store.SaveSnapshot(w.snap); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// goOffline switches to the board snapshot after err showed that Trello is
+// unreachable, and reports whether the caller should retry.
+func (w *cardWriter) goOffline(err error) bool {
+	if w.snap != nil || !isNetworkError(err) {
+		return false
+	}
+	if _, serr := store.LoadSnapshot(w.cfg.BoardID); serr != nil {
+		return false
+	}
+
+	fmt.Fprintln(os.Stderr, "Trello is unreachable; working from the offline snapshot.")
+	w.snap, w.client = mustLoadOffline(w.cfg)
+	w.reader = w.snap
+	return true
+}
+
+// findList matches a list by name, case-insensitively, or asks the user to
+// pick one when name is empty.
+func (w *cardWriter) findList(name string) *trello.List {
+	if name == "" {
+		list, err := PromptForList(w.reader, w.cfg.BoardID)
+		if err != nil && w.goOffline(err) {
+			list, err = PromptForList(w.reader, w.cfg.BoardID)
+		}
+		if err != nil {
+			log.Fatalf("Failed to select list: %v", err)
+		}
+		return list
+	}
+
+	lists, err := w.reader.GetLists(w.cfg.BoardID)
+	if err != nil && w.goOffline(err) {
+		lists, err = w.reader.GetLists(w.cfg.BoardID)
+	}
+	if err != nil {
+		log.Fatalf("Failed to get lists: %v", err)
+	}
+	for i, list := range lists {
+		if strings.EqualFold(list.Name, name) {
+			return &lists[i]
+		}
+	}
+	log.Fatalf("No list named %q on this board", name)
+	return nil
+}
+
+// findMember asks the user to pick one of the board's members.
+func (w *cardWriter) findMember() *trello.Member {
+	member, err := PromptForMember(w.reader, w.cfg.BoardID)
+	if err != nil && w.goOffline(err) {
+		member, err = PromptForMember(w.reader, w.cfg.BoardID)
+	}
+	if err != nil {
+		log.Fatalf("Failed to select member: %v", err)
+	}
+	return member
+}
+
+func (w *cardWriter) findCard(ref string) *trello.DetailedCard {
+	card, err := resolveCard(w.reader, w.cfg.BoardID, ref)
+	if err != nil && w.goOffline(err) {
+		card, err = resolveCard(w.reader, w.cfg.BoardID, ref)
+	}
+	if err != nil {
+		log.Fatalf("Failed to find card: %v", err)
+	}
+	return card
+}
+
+// isNetworkError reports whether err means Trello could not be reached, as
+// opposed to Trello rejecting the request.
+func isNetworkError(err error) bool {
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
+// requestNotSent reports whether err stopped the request before it could
+// reach Trello: the name didn't resolve or no connection was made.
+func requestNotSent(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && (opErr.Op == "dial" || opErr.Op == "proxyconnect")
+}
+
+// canQueue reports whether op may be queued after sending it failed with err.
+// After a timeout or a dropped connection Trello may already have made a
+// create or comment, and replaying it would make it twice; only moves are
+// safe to repeat.
+func canQueue(op store.Op, err error) bool {
+	return requestNotSent(err) || (isNetworkError(err) && op.Idempotent())
+}
+
+// unknownOutcome explains a failure that canQueue refused to queue.
+func unknownOutcome(err error) error {
+	if !isNetworkError(err) {
+		return err
+	}
+	return fmt.Errorf("lost contact with Trello before it answered, so the change may or may not have been made; check the board before trying again: %w", err)
+}
+
+func runCreateCommand(args []string) {
+	fs := flag.NewFlagSet("create", flag.ExitOnError)
+	listName := fs.String("list", "", "List to create the card in (asks when omitted)")
+	desc := fs.String("desc", "", "Card description")
+	assign := fs.Bool("assign", false, "Pick a board member to assign the card to")
+	offline := fs.Bool("offline", false, "Queue the card to be created when back online")
+	addClientFlags(fs)
+	fs.Parse(args)
+
+	name := strings.TrimSpace(strings.Join(fs.Args(), " "))
+	if name == "" {
+		log.Fatalf("Usage: trello_cli create [--list <list>] [--desc <text>] [--assign] <title>")
+	}
+
+	w := mustLoadWriter(*offline)
+	list := w.findList(*listName)
+	op := store.Op{
+		Kind:     store.OpCreate,
+		ListID:   list.ID,
+		ListName: list.Name,
+		Name:     name,
+		Desc:     *desc,
+	}
+	if *assign {
+		member := w.findMember()
+		op.MemberID, op.MemberUsername = member.ID, member.Username
+	}
+	w.submit(op)
+}
+
+func runCommentCommand(args []string) {
+	fs := flag.NewFlagSet("comment", flag.ExitOnError)
+	offline := fs.Bool("offline", false, "Queue the comment to be posted when back online")
+	addClientFlags(fs)
+	fs.Parse(args)
+
+	if fs.NArg() < 1 {
+		log.Fatalf("Usage: trello_cli comment [card] <text>")
+	}
+
+	w := mustLoadWriter(*offline)
+	ref, words := splitCardArgs(w.cfg, fs.Args())
+	text := strings.TrimSpace(strings.Join(words, " "))
+	if text == "" {
+		log.Fatalf("Comment text is empty")
+	}
+	card := w.findCard(ref)
+	w.submit(store.Op{
+		Kind:      store.OpComment,
+		CardID:    card.ID,
+		CardShort: card.IDShort,
+		CardName:  card.Name,
+		Text:      text,
+	})
+}
+
+func runMoveCommand(args []string) {
+	fs := flag.NewFlagSet("move", flag.ExitOnError)
+	offline := fs.Bool("offline", false, "Queue the move to be made when back online")
+	addClientFlags(fs)
+	fs.Parse(args)
+
+	w := mustLoadWriter(*offline)
+	ref, words := splitCardArgs(w.cfg, fs.Args())
+	card := w.findCard(ref)
+	list := w.findList(strings.TrimSpace(strings.Join(words, " ")))
+
+	if list.ID == card.IDList {
+		fmt.Printf("#%d is already in %s\n", card.IDShort, list.Name)
+		return
+	}
+
+	fromName := "Unknown"
+	if card.List != nil {
+		fromName = card.List.Name
+	}
+	w.submit(store.Op{
+		Kind:         store.OpMove,
+		CardID:       card.ID,
+		CardShort:    card.IDShort,
+		CardName:     card.Name,
+		ListID:       list.ID,
+		ListName:     list.Name,
+		FromListID:   card.IDList,
+		FromListName: fromName,
+	})
+}
+
+// applyOp sends a single change to Trello and describes the result.
+func applyOp(client *trello.Client, op store.Op) (string, error) {
+	switch op.Kind {
+	case store.OpCreate:
+		var memberIDs []string
+		if op.MemberID != "" {
+			memberIDs = []string{op.MemberID}
+		}
+		card, err := client.CreateCard(op.ListID, op.Name, op.Desc, memberIDs)
+		if err != nil {
+			return "", fmt.Errorf("failed to create card: %w", err)
+		}
+		return fmt.Sprintf("Created #%d %s in %s: %s", card.IDShort, card.Name, op.ListName, cardURL(*card)), nil
+	case store.OpComment:
+		if _, err := client.AddComment(op.CardID, op.Text); err != nil {
+			return "", fmt.Errorf("failed to add comment: %w", err)
+		}
+		return fmt.Sprintf("Commented on #%d", op.CardShort), nil
+	case store.OpMove:
+		if err := client.MoveCard(op.CardID, op.ListID); err != nil {
+			return "", fmt.Errorf("failed to move card: %w", err)
+		}
+		return fmt.Sprintf("Moved #%d to %s", op.CardShort, op.ListName), nil
+	}
+	return "", fmt.Errorf("unknown queued change %q", op.Kind)
+}
+
+// applyToSnapshot mirrors a queued change in the offline snapshot. New cards
+// have no number until Trello creates them, so they appear after the next
+// sync.
+func applyToSnapshot(snap *store.Snapshot, op store.Op) {
+	switch op.Kind {
+	case store.OpMove:
+		for i := range snap.Cards {
+			if snap.Cards[i].ID == op.CardID {
+				snap.Cards[i].IDList = op.ListID
+			}
+		}
+	case store.OpComment:
+		var comment trello.Comment
+		comment.ID = fmt.Sprintf("queued-%d", op.Seq)
+		comment.Data.Text = op.Text
+		comment.Data.Card.ID = op.CardID
+		comment.Date = op.QueuedAt.UTC().Format(time.RFC3339)
+		comment.MemberCreator.FullName = "You (not sent yet)"
+		snap.Comments = append([]trello.Comment{comment}, snap.Comments...)
+	}
+}